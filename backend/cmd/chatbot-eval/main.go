@@ -0,0 +1,298 @@
+// chatbot-eval answers a labelled JSONL dataset of
+// {"message": ..., "category": ..., "crisis": ...} lines with Buddy's real
+// response engine, backed by the seed scenarios instead of the database, and
+// prints per-category precision/recall of the scenario served, crisis recall
+// and a confusion matrix. An empty category means no scenario should be
+// served. Crisis replies do not depend on the category, so crisis examples
+// only count towards crisis recall. Lines may also set "lang" ("vi" or "en")
+// to check language detection.
+//
+// Messages go through the redaction rules the way SendChat handles them: the
+// reply is built from the original text and only the redacted copy would be
+// stored. Chat summaries classify that stored copy, so the run also reports
+// how many messages redaction moves to another category.
+//
+// It exits non-zero when crisis recall is below 100% or any category scores
+// below the baseline. After an intended improvement, record the new numbers
+// with -write-baseline.
+//
+//	go run ./cmd/chatbot-eval
+//	go run ./cmd/chatbot-eval -write-baseline
+package main
+
+import (
+	"bufio"
+	"context"
+	"edu-web-backend/internal/chatbot"
+	"edu-web-backend/internal/handlers"
+	"edu-web-backend/internal/redact"
+	"edu-web-backend/internal/repository"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+// none labels messages without a category in the report.
+const none = "(none)"
+
+// tolerance absorbs float rounding when comparing against the baseline.
+const tolerance = 1e-9
+
+type example struct {
+	Message  string `json:"message"`
+	Category string `json:"category"`
+	Crisis   bool   `json:"crisis"`
+	Lang     string `json:"lang"`
+}
+
+type score struct {
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+}
+
+type baseline struct {
+	Accuracy   float64          `json:"accuracy"`
+	Categories map[string]score `json:"categories"`
+}
+
+func main() {
+	dataPath := flag.String("data", "internal/chatbot/testdata/eval.jsonl", "labelled JSONL dataset")
+	baselinePath := flag.String("baseline", "internal/chatbot/testdata/baseline.json", "baseline scores to compare against")
+	writeBaseline := flag.Bool("write-baseline", false, "overwrite the baseline with this run's scores")
+	rulesPath := flag.String("rules", os.Getenv("REDACTION_RULES_FILE"), "optional JSON file overriding the default redaction rules")
+	verbose := flag.Bool("v", false, "print every misclassified message")
+	flag.Parse()
+
+	rules, err := redact.LoadRules(*rulesPath)
+	if err != nil {
+		log.Fatalf("Rules error: %v", err)
+	}
+	redactor, err := redact.New(rules)
+	if err != nil {
+		log.Fatalf("Rules error: %v", err)
+	}
+
+	examples, err := loadExamples(*dataPath)
+	if err != nil {
+		log.Fatalf("Dataset error: %v", err)
+	}
+	if len(examples) == 0 {
+		log.Fatalf("Dataset %s is empty", *dataPath)
+	}
+
+	labels := append(append([]string{}, chatbot.Categories()...), none)
+	confusion := map[string]map[string]int{}
+	for _, l := range labels {
+		confusion[l] = map[string]int{}
+	}
+	ctx := context.Background()
+	store := memoryStore{scenarios: repository.DefaultScenarios()}
+	var scored, correct, crisisTotal, crisisFound, crisisFalse, langTotal, langCorrect, redactedMoved int
+	for _, ex := range examples {
+		message := strings.TrimSpace(ex.Message)
+		stored, _ := redactor.Redact(message)
+
+		// Chat requests default to detecting the language, as here.
+		lang := chatbot.DetectLanguage(message)
+		if ex.Lang != "" {
+			langTotal++
+			if lang == ex.Lang {
+				langCorrect++
+			} else if *verbose {
+				fmt.Printf("LANG expected %s got %s: %s\n", ex.Lang, lang, ex.Message)
+			}
+		}
+
+		got := handlers.Reply(ctx, store, message, lang)
+		if stored != message && chatbot.DetectCategory(stored) != got.Category {
+			redactedMoved++
+			if *verbose {
+				fmt.Printf("REDACTED %-12s -> %-12s %s\n", orNone(got.Category), orNone(chatbot.DetectCategory(stored)), stored)
+			}
+		}
+		if !ex.Crisis {
+			scored++
+			want, have := orNone(ex.Category), orNone(got.Category)
+			confusion[want][have]++
+			if want == have {
+				correct++
+			} else if *verbose {
+				fmt.Printf("MISS expected %-12s got %-12s %s\n", want, have, ex.Message)
+			}
+		}
+
+		if ex.Crisis {
+			crisisTotal++
+			if got.Crisis {
+				crisisFound++
+			} else {
+				fmt.Printf("CRISIS MISSED: %s\n", ex.Message)
+			}
+		} else if got.Crisis {
+			crisisFalse++
+			if *verbose {
+				fmt.Printf("CRISIS FALSE ALARM: %s\n", ex.Message)
+			}
+		}
+	}
+
+	current := baseline{
+		Accuracy:   ratio(correct, scored),
+		Categories: map[string]score{},
+	}
+	fmt.Printf("\n%d messages, category accuracy %.3f over %d non-crisis\n\n", len(examples), current.Accuracy, scored)
+	fmt.Printf("%-12s %9s %9s %8s\n", "category", "precision", "recall", "support")
+	for _, l := range chatbot.Categories() {
+		var tp, fp, fn int
+		for _, other := range labels {
+			if other == l {
+				continue
+			}
+			fp += confusion[other][l]
+			fn += confusion[l][other]
+		}
+		tp = confusion[l][l]
+		s := score{Precision: ratio(tp, tp+fp), Recall: ratio(tp, tp+fn)}
+		current.Categories[l] = s
+		fmt.Printf("%-12s %9.3f %9.3f %8d\n", l, s.Precision, s.Recall, tp+fn)
+	}
+
+	crisisRecall := ratio(crisisFound, crisisTotal)
+	fmt.Printf("\ncrisis recall %.3f (%d/%d), false alarms %d\n", crisisRecall, crisisFound, crisisTotal, crisisFalse)
+
+	fmt.Printf("redaction changes the stored category of %d messages\n", redactedMoved)
+	if langTotal > 0 {
+		fmt.Printf("language detection %.3f (%d/%d)\n", ratio(langCorrect, langTotal), langCorrect, langTotal)
+	}
+
+	printConfusion(labels, confusion)
+
+	if *writeBaseline {
+		if err := saveBaseline(*baselinePath, current); err != nil {
+			log.Fatalf("Baseline error: %v", err)
+		}
+		fmt.Printf("\nbaseline written to %s\n", *baselinePath)
+	}
+
+	failed := false
+	if crisisTotal == 0 {
+		fmt.Println("\nFAIL: dataset has no crisis examples")
+		failed = true
+	} else if crisisFound < crisisTotal {
+		fmt.Println("\nFAIL: crisis recall must be 100%")
+		failed = true
+	}
+	if !*writeBaseline {
+		prev, err := loadBaseline(*baselinePath)
+		if err != nil {
+			log.Fatalf("Baseline error: %v", err)
+		}
+		for _, r := range regressions(prev, current) {
+			fmt.Println("REGRESSION: " + r)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func loadExamples(path string) ([]example, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var examples []example
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var ex example
+		if err := json.Unmarshal(scanner.Bytes(), &ex); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if ex.Category != "" && !chatbot.IsCategory(ex.Category) {
+			return nil, fmt.Errorf("line %d: unknown category %q", line, ex.Category)
+		}
+		if ex.Lang != "" && !chatbot.IsLanguage(ex.Lang) {
+			return nil, fmt.Errorf("line %d: unknown lang %q", line, ex.Lang)
+		}
+		examples = append(examples, ex)
+	}
+	return examples, scanner.Err()
+}
+
+func loadBaseline(path string) (baseline, error) {
+	var b baseline
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return b, err
+	}
+	return b, json.Unmarshal(data, &b)
+}
+
+func saveBaseline(path string, b baseline) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// regressions lists every score that dropped below the baseline.
+func regressions(prev, cur baseline) []string {
+	var out []string
+	if cur.Accuracy < prev.Accuracy-tolerance {
+		out = append(out, fmt.Sprintf("accuracy %.3f < %.3f", cur.Accuracy, prev.Accuracy))
+	}
+	for _, name := range chatbot.Categories() {
+		p, ok := prev.Categories[name]
+		if !ok {
+			continue
+		}
+		c := cur.Categories[name]
+		if c.Precision < p.Precision-tolerance {
+			out = append(out, fmt.Sprintf("%s precision %.3f < %.3f", name, c.Precision, p.Precision))
+		}
+		if c.Recall < p.Recall-tolerance {
+			out = append(out, fmt.Sprintf("%s recall %.3f < %.3f", name, c.Recall, p.Recall))
+		}
+	}
+	return out
+}
+
+// printConfusion prints expected categories as rows and detected ones as columns.
+func printConfusion(labels []string, confusion map[string]map[string]int) {
+	fmt.Printf("\nconfusion matrix (rows: expected, columns: detected)\n%-12s", "")
+	for _, l := range labels {
+		fmt.Printf(" %5.5s", l)
+	}
+	fmt.Println()
+	for _, want := range labels {
+		fmt.Printf("%-12s", want)
+		for _, have := range labels {
+			fmt.Printf(" %5d", confusion[want][have])
+		}
+		fmt.Println()
+	}
+}
+
+func orNone(category string) string {
+	if category == "" {
+		return none
+	}
+	return category
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 1
+	}
+	return float64(n) / float64(d)
+}
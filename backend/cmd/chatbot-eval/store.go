@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"edu-web-backend/internal/models"
+	"strings"
+)
+
+// memoryStore answers the response engine's lookups from the seed scenarios
+// the way the psych_scenarios queries do: keywords match triggers ignoring
+// case and the first row wins.
+type memoryStore struct {
+	scenarios []models.PsychScenario
+}
+
+func (m memoryStore) GetScenarioByKeyword(_ context.Context, keyword, lang string) (*models.PsychScenario, error) {
+	keyword = strings.ToLower(keyword)
+	for i, s := range m.scenarios {
+		if s.Lang == lang && strings.Contains(strings.ToLower(s.Trigger), keyword) {
+			return &m.scenarios[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetScenarioByCategory returns the category's first scenario. The database
+// picks one at random, which does not change the category served.
+func (m memoryStore) GetScenarioByCategory(_ context.Context, category, lang string) (*models.PsychScenario, error) {
+	for i, s := range m.scenarios {
+		if s.Lang == lang && s.Category == category {
+			return &m.scenarios[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetSafetyPlan finds no plan: evaluated messages are answered as a guest.
+func (memoryStore) GetSafetyPlan(context.Context, int) (*models.SafetyPlan, error) {
+	return nil, nil
+}
@@ -3,10 +3,24 @@ package main
 import (
 	"context"
 	"edu-web-backend/config"
+	"edu-web-backend/internal/booking"
 	"edu-web-backend/internal/handlers"
 	"edu-web-backend/internal/middleware"
+	"edu-web-backend/internal/models"
+	"edu-web-backend/internal/notify"
+	"edu-web-backend/internal/qr"
+	"edu-web-backend/internal/qrbatch"
+	"edu-web-backend/internal/ratelimit"
+	"edu-web-backend/internal/redact"
 	"edu-web-backend/internal/repository"
+	"edu-web-backend/internal/retention"
+	"edu-web-backend/internal/storage"
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -36,39 +50,169 @@ func main() {
 	}
 	log.Println("Auth tables migrated successfully")
 
+	if err := db.MigrateChat(ctx); err != nil {
+		log.Fatalf("Chat migration error: %v", err)
+	}
+	log.Println("Chat tables migrated successfully")
+
+	if err := db.MigrateWellbeing(ctx); err != nil {
+		log.Fatalf("Wellbeing migration error: %v", err)
+	}
+	log.Println("Wellbeing tables migrated successfully")
+
+	if err := db.MigrateCounseling(ctx); err != nil {
+		log.Fatalf("Counseling migration error: %v", err)
+	}
+	log.Println("Counseling tables migrated successfully")
+
+	if err := db.MigrateQRCodes(ctx); err != nil {
+		log.Fatalf("QR code migration error: %v", err)
+	}
+
+	if err := db.MigrateAttendance(ctx); err != nil {
+		log.Fatalf("Attendance migration error: %v", err)
+	}
+
+	if err := db.MigrateMedia(ctx); err != nil {
+		log.Fatalf("Media migration error: %v", err)
+	}
+
+	if err := db.MigrateRateLimits(ctx); err != nil {
+		log.Fatalf("Rate limit migration error: %v", err)
+	}
+
+	purger := retention.NewPurger(db, []retention.Policy{
+		{Table: "chat_messages", MaxAge: cfg.ChatRetention},
+		{Table: "direct_messages", MaxAge: cfg.DMRetention},
+	}, cfg.PurgeBatchSize, cfg.RetentionMode == "anonymize")
+
+	// `server purge [-dry-run]` runs the retention job once and exits.
+	if len(os.Args) > 1 && os.Args[1] == "purge" {
+		runPurge(ctx, purger, os.Args[2:])
+		return
+	}
+
+	// `server set-role <username> <student|teacher|counselor|admin>` grants staff access.
+	if len(os.Args) > 1 && os.Args[1] == "set-role" {
+		runSetRole(ctx, db, os.Args[2:])
+		return
+	}
+
+	// `server qr-bulk [-o codes.pdf|codes.zip] [-format png] [-size 512] <file.csv>`
+	// creates link codes from a CSV and writes a printable sheet or a ZIP.
+	if len(os.Args) > 1 && os.Args[1] == "qr-bulk" {
+		runQRBulk(ctx, db, cfg.PublicURL, os.Args[2:])
+		return
+	}
+
 	if err := db.SeedData(ctx); err != nil {
 		log.Printf("Seed warning: %v", err)
 	} else {
 		log.Println("Data seeded successfully")
 	}
 
+	if err := db.SeedContentTags(ctx); err != nil {
+		log.Printf("Content tag seed warning: %v", err)
+	}
+
 	if err := db.SeedScenarios(ctx); err != nil {
 		log.Printf("Scenario seed warning: %v", err)
 	} else {
 		log.Println("Psychological scenarios seeded successfully")
 	}
 
-	h := handlers.NewHandler(db)
+	go purger.Schedule(ctx, cfg.PurgeInterval)
+
+	notifier := notify.NewDirectMessageNotifier(db)
+	go booking.NewReminder(db, notifier, cfg.ReminderLead, cfg.Location).Schedule(ctx, cfg.ReminderInterval)
+
+	rules, err := redact.LoadRules(cfg.RedactionRulesFile)
+	if err != nil {
+		log.Fatalf("Redaction rules error: %v", err)
+	}
+	redactor, err := redact.New(rules)
+	if err != nil {
+		log.Fatalf("Redaction rules error: %v", err)
+	}
+	log.Printf("PII redaction rules loaded: %v", redactor.RuleNames())
+
+	mediaStore, err := storage.NewLocal(cfg.MediaDir)
+	if err != nil {
+		log.Fatalf("Media storage error: %v", err)
+	}
+
+	h := handlers.NewHandler(db, redactor, notifier, cfg.Location, cfg.PublicURL, cfg.FrontendURL, mediaStore, cfg.MediaMaxBytes)
+
+	var limitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimitStore == "postgres" {
+		limitStore = ratelimit.NewPostgresStore(db)
+	}
+	chatLimit := middleware.RateLimit(limitStore, ratelimit.Policy{Name: "chat", Burst: cfg.ChatRateLimit, Per: time.Minute})
+	qrLimit := middleware.RateLimit(limitStore, ratelimit.Policy{Name: "qr", Burst: cfg.QRRateLimit, Per: time.Hour})
+	scanLimit := middleware.RateLimit(limitStore, ratelimit.Policy{Name: "scan", Burst: cfg.ScanRateLimit, Per: time.Minute})
+	sessionLimit := middleware.RateLimit(limitStore, ratelimit.Policy{Name: "session", Burst: cfg.SessionRateLimit, Per: time.Hour})
+	questionLimit := middleware.RateLimit(limitStore, ratelimit.Policy{Name: "question", Burst: cfg.QuestionRateLimit, Per: time.Hour})
 
 	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("TRUSTED_PROXIES error: %v", err)
+	}
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{cfg.FrontendURL, "http://localhost:3000", "http://localhost:3001"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		AllowCredentials: true, // required for cookies to be sent cross-origin
-		ExposeHeaders:    []string{"Set-Cookie"},
+		ExposeHeaders:    []string{"Set-Cookie", "RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"},
 	}))
 
+	// Printed QR codes encode these short links. Each hit records a scan, so
+	// guests are limited per IP, generously since a class shares one.
+	r.GET("/q/:slug", scanLimit, h.RedirectQR)
+
 	api := r.Group("/api/v1")
 	{
 		api.GET("/health", h.HealthCheck)
 		api.GET("/videos", h.GetVideos)
 		api.GET("/audios", h.GetAudios)
-		api.GET("/qrcodes", h.GetQRCodes)
-		api.POST("/qrcodes/generate", h.GenerateQR)
-		api.GET("/chat/:session_id", h.GetChatHistory)
-		api.POST("/chat", h.SendChat)
+		api.POST("/videos", middleware.AuthRequired(), h.RequireRole(models.RoleTeacher, models.RoleCounselor, models.RoleAdmin), h.CreateVideo)
+		api.POST("/audios", middleware.AuthRequired(), h.RequireRole(models.RoleTeacher, models.RoleCounselor, models.RoleAdmin), h.CreateAudio)
+		api.GET("/media-links", h.NormalizeMediaLink)
+		api.GET("/media", middleware.AuthRequired(), h.RequireRole(models.RoleTeacher, models.RoleCounselor, models.RoleAdmin), h.GetMediaFiles)
+		api.POST("/media", middleware.AuthRequired(), h.RequireRole(models.RoleTeacher, models.RoleCounselor, models.RoleAdmin), h.UploadMedia)
+		api.GET("/media/:id/file", h.ServeMedia)
+		api.HEAD("/media/:id/file", h.ServeMedia)
+		api.DELETE("/media/:id", middleware.AuthRequired(), h.RequireRole(models.RoleTeacher, models.RoleCounselor, models.RoleAdmin), h.DeleteMedia)
+		api.GET("/qrcodes", middleware.OptionalAuth(), h.GetQRCodes)
+		api.POST("/qrcodes/generate", middleware.AuthRequired(), qrLimit, h.GenerateQR)
+		api.GET("/qrcodes/types", h.GetQRCodeTypes)
+		api.POST("/qrcodes/decode", middleware.OptionalAuth(), qrLimit, h.DecodeQR)
+		api.GET("/qrcodes/:id/image", middleware.OptionalAuth(), h.GetQRCodeImage)
+		api.POST("/qrcodes/:id/image", middleware.OptionalAuth(), qrLimit, h.RenderQRCodeImage)
+		api.GET("/qrcodes/logos", h.GetQRLogos)
+		api.GET("/qrcodes/logos/:id", h.GetQRLogoImage)
+		api.POST("/qrcodes/logos", middleware.AuthRequired(), h.RequireRole(models.RoleCounselor, models.RoleAdmin), h.UploadQRLogo)
+		api.PATCH("/qrcodes/:id", middleware.AuthRequired(), h.UpdateQRCode)
+		api.DELETE("/qrcodes/:id", middleware.AuthRequired(), h.DeleteQRCode)
+		api.PUT("/qrcodes/:id/target", middleware.AuthRequired(), h.UpdateQRCodeTarget)
+		api.PUT("/qrcodes/:id/payload", middleware.AuthRequired(), h.UpdateQRCodePayload)
+		api.POST("/qrcodes/bulk", middleware.AuthRequired(), h.RequireRole(models.RoleCounselor, models.RoleAdmin), h.BulkCreateQR)
+		api.GET("/qrcodes/:id/scans", middleware.AuthRequired(), h.GetQRScanStats)
+
+		chat := api.Group("/chat")
+		chat.Use(middleware.OptionalAuth())
+		{
+			chat.POST("", chatLimit, h.SendChat)
+			chat.POST("/sessions", sessionLimit, h.CreateChatSession)
+			chat.GET("/sessions", h.ListChatSessions)
+			chat.DELETE("/sessions/:session_id", h.DeleteChatSession)
+			chat.POST("/sessions/:session_id/share", middleware.AuthRequired(), h.ShareChatSummary)
+			chat.GET("/exercises", h.ListExercises)
+			chat.GET("/:session_id", h.GetChatHistory)
+		}
+
+		api.POST("/questions", questionLimit, h.AskAnonymousQuestion)
+		api.POST("/questions/reply", h.GetAnonymousReply)
 
 		auth := api.Group("/auth")
 		{
@@ -84,6 +228,65 @@ func main() {
 			protected.POST("/messages", h.SendDirectMessage)
 			protected.GET("/messages/:other_user_id", h.GetDirectMessages)
 			protected.GET("/users", h.GetUsers)
+
+			protected.POST("/mood", h.CreateMoodEntry)
+			protected.GET("/mood", h.GetMoodEntries)
+			protected.GET("/mood/trends", h.GetMoodTrends)
+
+			protected.GET("/screenings", h.GetQuestionnaires)
+			protected.GET("/screenings/results", h.GetScreeningResults)
+			protected.GET("/screenings/:code", h.GetQuestionnaire)
+			protected.POST("/screenings/:code", h.SubmitScreening)
+
+			protected.GET("/safety-plan", h.GetSafetyPlan)
+			protected.PUT("/safety-plan", h.SaveSafetyPlan)
+			protected.GET("/safety-plan/versions", h.GetSafetyPlanVersions)
+			protected.PUT("/safety-plan/share", h.ShareSafetyPlan)
+			protected.DELETE("/safety-plan/share", h.UnshareSafetyPlan)
+
+			protected.GET("/counselors", h.ListCounselors)
+			protected.GET("/counselors/:id/slots", h.GetCounselorSlots)
+			protected.POST("/appointments", h.BookAppointment)
+			protected.GET("/appointments", h.GetAppointments)
+			protected.GET("/appointments/ics", h.ExportAppointmentsICS)
+			protected.GET("/appointments/:id/ics", h.ExportAppointmentICS)
+			protected.POST("/appointments/:id/cancel", h.CancelAppointment)
+			protected.POST("/appointments/:id/reschedule", h.RescheduleAppointment)
+
+			protected.POST("/attendance/check-in", h.CheckIn)
+		}
+
+		teacher := api.Group("/attendance/sessions")
+		teacher.Use(middleware.AuthRequired(), h.RequireRole(models.RoleTeacher, models.RoleCounselor, models.RoleAdmin))
+		{
+			teacher.POST("", h.StartAttendance)
+			teacher.GET("", h.GetAttendanceSessions)
+			teacher.GET("/:id/code", h.GetAttendanceCode)
+			teacher.GET("/:id/code/image", h.GetAttendanceCodeImage)
+			teacher.POST("/:id/end", h.EndAttendance)
+			teacher.GET("/:id/check-ins", h.GetAttendanceCheckIns)
+			teacher.GET("/:id/export", h.ExportAttendanceCSV)
+		}
+
+		counselor := api.Group("/counselor")
+		counselor.Use(middleware.AuthRequired(), h.RequireRole(models.RoleCounselor, models.RoleAdmin))
+		{
+			counselor.GET("/screenings/summary", h.GetScreeningSummary)
+			counselor.GET("/safety-plans", h.GetSharedSafetyPlans)
+			counselor.PUT("/safety-plans/:user_id", h.UpdateStudentSafetyPlan)
+
+			counselor.GET("/questions", h.ListAnonymousQuestions)
+			counselor.POST("/questions/:id/claim", h.ClaimAnonymousQuestion)
+			counselor.POST("/questions/:id/answer", h.AnswerAnonymousQuestion)
+			counselor.POST("/questions/:id/close", h.CloseAnonymousQuestion)
+
+			counselor.GET("/chat-summaries", h.GetChatSummaryShares)
+
+			counselor.GET("/availability", h.GetAvailability)
+			counselor.PUT("/availability", h.SetAvailability)
+			counselor.GET("/availability/exceptions", h.GetAvailabilityExceptions)
+			counselor.POST("/availability/exceptions", h.CreateAvailabilityException)
+			counselor.DELETE("/availability/exceptions/:id", h.DeleteAvailabilityException)
 		}
 	}
 
@@ -92,3 +295,90 @@ func main() {
 		log.Fatalf("Server error: %v", err)
 	}
 }
+
+func runPurge(ctx context.Context, purger *retention.Purger, args []string) {
+	fs := flag.NewFlagSet("purge", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report how many rows would be purged without changing anything")
+	fs.Parse(args)
+
+	report, err := purger.Run(ctx, *dryRun)
+	if err != nil {
+		log.Fatalf("Purge error: %v", err)
+	}
+	fmt.Println(report)
+}
+
+func runSetRole(ctx context.Context, db *repository.DB, args []string) {
+	if len(args) != 2 {
+		log.Fatalf("Usage: set-role <username> <%s|%s|%s|%s>", models.RoleStudent, models.RoleTeacher, models.RoleCounselor, models.RoleAdmin)
+	}
+	username, role := args[0], args[1]
+	if role != models.RoleStudent && role != models.RoleTeacher && role != models.RoleCounselor && role != models.RoleAdmin {
+		log.Fatalf("Unknown role %q", role)
+	}
+	found, err := db.SetUserRole(ctx, username, role)
+	if err != nil {
+		log.Fatalf("Set role error: %v", err)
+	}
+	if !found {
+		log.Fatalf("User %q not found", username)
+	}
+	fmt.Printf("%s is now %s\n", username, role)
+}
+
+func runQRBulk(ctx context.Context, db *repository.DB, publicURL string, args []string) {
+	opts := qr.DefaultOptions()
+	fs := flag.NewFlagSet("qr-bulk", flag.ExitOnError)
+	out := fs.String("o", "qrcodes.pdf", "output file; a .zip name writes one image per code, anything else an A4 PDF sheet")
+	fs.StringVar(&opts.Format, "format", opts.Format, "image format inside the ZIP (png, svg or pdf)")
+	fs.IntVar(&opts.Size, "size", opts.Size, "image size in pixels inside the ZIP")
+	fs.StringVar(&opts.ECC, "ecc", opts.ECC, "error correction level (L, M, Q or H)")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		log.Fatalf("Usage: qr-bulk [-o codes.pdf|codes.zip] [-format png] [-size 512] <file.csv>")
+	}
+	if err := opts.Validate(); err != nil {
+		log.Fatalf("Invalid options: %v", err)
+	}
+
+	in, err := os.Open(fs.Arg(0))
+	if err != nil {
+		log.Fatalf("Open CSV: %v", err)
+	}
+	codes, rowErrs, err := qrbatch.ParseCSV(in)
+	in.Close()
+	for _, e := range rowErrs {
+		fmt.Fprintf(os.Stderr, "line %d: %s\n", e.Line, e.Error)
+	}
+	if err != nil {
+		log.Fatalf("CSV error: %v", err)
+	}
+
+	// Open the output before saving so an unwritable path does not leave
+	// codes in the database that were never exported.
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("Create output: %v", err)
+	}
+	codes, err = qrbatch.Create(ctx, db, codes, publicURL)
+	if err != nil {
+		f.Close()
+		os.Remove(*out)
+		log.Fatalf("Create error: %v", err)
+	}
+	if strings.HasSuffix(strings.ToLower(*out), ".zip") {
+		err = qrbatch.ZIP(f, codes, opts)
+	} else {
+		var sheet []byte
+		if sheet, err = qrbatch.Sheet(codes, opts); err == nil {
+			_, err = f.Write(sheet)
+		}
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Fatalf("Write output: %v", err)
+	}
+	fmt.Printf("created %d QR codes, wrote %s\n", len(codes), *out)
+}
@@ -0,0 +1,65 @@
+// redact-check runs the PII redaction rules against a JSONL corpus of
+// {"input": ..., "expected": ...} lines and exits non-zero on any mismatch.
+//
+//	go run ./cmd/redact-check -corpus internal/redact/testdata/corpus.jsonl
+package main
+
+import (
+	"bufio"
+	"edu-web-backend/internal/redact"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	corpusPath := flag.String("corpus", "internal/redact/testdata/corpus.jsonl", "JSONL corpus of input/expected pairs")
+	rulesPath := flag.String("rules", os.Getenv("REDACTION_RULES_FILE"), "optional JSON file overriding the default rules")
+	flag.Parse()
+
+	rules, err := redact.LoadRules(*rulesPath)
+	if err != nil {
+		log.Fatalf("Rules error: %v", err)
+	}
+	redactor, err := redact.New(rules)
+	if err != nil {
+		log.Fatalf("Rules error: %v", err)
+	}
+
+	f, err := os.Open(*corpusPath)
+	if err != nil {
+		log.Fatalf("Corpus error: %v", err)
+	}
+	defer f.Close()
+
+	var total, failed int
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var tc struct {
+			Input    string `json:"input"`
+			Expected string `json:"expected"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &tc); err != nil {
+			log.Fatalf("Corpus line %d: %v", line, err)
+		}
+		total++
+		got, _ := redactor.Redact(tc.Input)
+		if got != tc.Expected {
+			failed++
+			fmt.Printf("FAIL line %d\n  input:    %s\n  expected: %s\n  got:      %s\n", line, tc.Input, tc.Expected, got)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatalf("Corpus error: %v", err)
+	}
+
+	fmt.Printf("%d/%d cases passed\n", total-failed, total)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -3,14 +3,56 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // SCHOOL_TIMEZONE must resolve on hosts without zoneinfo
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DBUrl      string
-	Port       string
+	DBUrl       string
+	Port        string
 	FrontendURL string
+	// PublicURL is where this server is reachable from phones; QR short links
+	// are built on it.
+	PublicURL string
+
+	// Retention: how long chat content is kept (0 = forever) and how it is purged.
+	ChatRetention  time.Duration
+	DMRetention    time.Duration
+	RetentionMode  string // "delete" or "anonymize"
+	PurgeInterval  time.Duration
+	PurgeBatchSize int
+
+	// RedactionRulesFile optionally overrides or extends the built-in PII rules.
+	RedactionRulesFile string
+
+	// Appointments: slots are defined in school time; reminders go out
+	// ReminderLead before the start and are checked every ReminderInterval.
+	Location         *time.Location
+	ReminderLead     time.Duration
+	ReminderInterval time.Duration
+
+	// Rate limiting: RateLimitStore is "memory" (per instance) or "postgres"
+	// (shared). Limits are requests per minute for chat and short-link scans
+	// and per hour for QR generation, new chat sessions and anonymous
+	// questions; 0 turns a limit off.
+	RateLimitStore    string
+	ChatRateLimit     int
+	QRRateLimit       int
+	ScanRateLimit     int
+	SessionRateLimit  int
+	QuestionRateLimit int
+	// TrustedProxies are the addresses or CIDRs allowed to set
+	// X-Forwarded-For. Guests are rate limited by client IP, so with none the
+	// connection's address is used and the header is ignored.
+	TrustedProxies []string
+
+	// Media uploads are kept in MediaDir; MediaMaxBytes bounds one file.
+	MediaDir      string
+	MediaMaxBytes int64
 }
 
 func Load() (*Config, error) {
@@ -31,9 +73,140 @@ func Load() (*Config, error) {
 		frontendURL = "http://localhost:3000"
 	}
 
+	publicURL := strings.TrimRight(os.Getenv("PUBLIC_URL"), "/")
+	if publicURL == "" {
+		publicURL = "http://localhost:" + port
+	}
+
+	// Default chat retention is one school term (~20 weeks).
+	chatDays, err := envInt("CHAT_RETENTION_DAYS", 140)
+	if err != nil {
+		return nil, err
+	}
+	dmDays, err := envInt("DM_RETENTION_DAYS", 365)
+	if err != nil {
+		return nil, err
+	}
+	purgeHours, err := envInt("PURGE_INTERVAL_HOURS", 24)
+	if err != nil {
+		return nil, err
+	}
+	batchSize, err := envInt("PURGE_BATCH_SIZE", 500)
+	if err != nil {
+		return nil, err
+	}
+
+	retentionMode := os.Getenv("RETENTION_MODE")
+	if retentionMode == "" {
+		retentionMode = "delete"
+	}
+	if retentionMode != "delete" && retentionMode != "anonymize" {
+		return nil, fmt.Errorf("RETENTION_MODE must be delete or anonymize")
+	}
+
+	tzName := os.Getenv("SCHOOL_TIMEZONE")
+	if tzName == "" {
+		tzName = "Asia/Ho_Chi_Minh"
+	}
+	loc, err := time.LoadLocation(tzName)
+	if err != nil {
+		return nil, fmt.Errorf("SCHOOL_TIMEZONE: %w", err)
+	}
+	reminderHours, err := envInt("REMINDER_LEAD_HOURS", 24)
+	if err != nil {
+		return nil, err
+	}
+	reminderMinutes, err := envInt("REMINDER_INTERVAL_MINUTES", 15)
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimitStore := os.Getenv("RATE_LIMIT_STORE")
+	if rateLimitStore == "" {
+		rateLimitStore = "memory"
+	}
+	if rateLimitStore != "memory" && rateLimitStore != "postgres" {
+		return nil, fmt.Errorf("RATE_LIMIT_STORE must be memory or postgres")
+	}
+	chatLimit, err := envInt("CHAT_RATE_LIMIT", 20)
+	if err != nil {
+		return nil, err
+	}
+	qrLimit, err := envInt("QR_RATE_LIMIT", 30)
+	if err != nil {
+		return nil, err
+	}
+	scanLimit, err := envInt("SCAN_RATE_LIMIT", 120)
+	if err != nil {
+		return nil, err
+	}
+	sessionLimit, err := envInt("SESSION_RATE_LIMIT", 30)
+	if err != nil {
+		return nil, err
+	}
+	questionLimit, err := envInt("QUESTION_RATE_LIMIT", 10)
+	if err != nil {
+		return nil, err
+	}
+
+	var trustedProxies []string
+	for _, p := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			trustedProxies = append(trustedProxies, p)
+		}
+	}
+
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "./media"
+	}
+	mediaMaxMB, err := envInt("MEDIA_MAX_MB", 500)
+	if err != nil {
+		return nil, err
+	}
+	if mediaMaxMB <= 0 {
+		return nil, fmt.Errorf("MEDIA_MAX_MB must be positive")
+	}
+
 	return &Config{
-		DBUrl:       dbUrl,
-		Port:        port,
-		FrontendURL: frontendURL,
+		DBUrl:          dbUrl,
+		Port:           port,
+		FrontendURL:    frontendURL,
+		PublicURL:      publicURL,
+		ChatRetention:  time.Duration(chatDays) * 24 * time.Hour,
+		DMRetention:    time.Duration(dmDays) * 24 * time.Hour,
+		RetentionMode:  retentionMode,
+		PurgeInterval:  time.Duration(purgeHours) * time.Hour,
+		PurgeBatchSize: batchSize,
+
+		RedactionRulesFile: os.Getenv("REDACTION_RULES_FILE"),
+
+		Location:         loc,
+		ReminderLead:     time.Duration(reminderHours) * time.Hour,
+		ReminderInterval: time.Duration(reminderMinutes) * time.Minute,
+
+		RateLimitStore:    rateLimitStore,
+		ChatRateLimit:     chatLimit,
+		QRRateLimit:       qrLimit,
+		ScanRateLimit:     scanLimit,
+		SessionRateLimit:  sessionLimit,
+		QuestionRateLimit: questionLimit,
+		TrustedProxies:    trustedProxies,
+
+		MediaDir:      mediaDir,
+		MediaMaxBytes: int64(mediaMaxMB) << 20,
 	}, nil
 }
+
+// envInt reads a non-negative integer env var, returning def when unset.
+func envInt(key string, def int) (int, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s must be a non-negative integer", key)
+	}
+	return n, nil
+}
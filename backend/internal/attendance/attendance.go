@@ -0,0 +1,114 @@
+// Package attendance signs the rotating codes a teacher shows for a class
+// check-in and exports the result. A code names the session and the time
+// window it was shown in, so a photo of it stops working shortly after the
+// screen moves on.
+package attendance
+
+import (
+	"edu-web-backend/internal/config"
+	"edu-web-backend/internal/models"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// audience keeps check-in codes apart from login and chat tokens, which are
+// signed with the same secret.
+const audience = "attendance"
+
+// Grace is how many windows after its own a code is still accepted, so a
+// student who scans just before it changes is not turned away.
+const Grace = 1
+
+// ErrStale means the code is genuine but no longer shown, e.g. a forwarded
+// photo.
+var ErrStale = errors.New("this code has changed; scan the one on the screen now")
+
+// ErrInvalid means the code was not issued by this server.
+var ErrInvalid = errors.New("not an attendance code")
+
+// Window numbers the rotation periods of a session since the Unix epoch.
+func Window(now time.Time, rotateSeconds int) int64 {
+	return now.Unix() / int64(rotateSeconds)
+}
+
+// RefreshIn is how long the code of the current window stays on screen.
+func RefreshIn(now time.Time, rotateSeconds int) time.Duration {
+	next := time.Unix((Window(now, rotateSeconds)+1)*int64(rotateSeconds), 0)
+	return next.Sub(now)
+}
+
+// Token signs the code for session s in the window containing now.
+func Token(s *models.AttendanceSession, now time.Time) (string, error) {
+	win := Window(now, s.RotateSeconds)
+	exp := time.Unix((win+1+Grace)*int64(s.RotateSeconds), 0)
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"aud": audience,
+		"sid": s.ID,
+		"win": win,
+		"exp": exp.Unix(),
+	}).SignedString(config.JWTSecret())
+}
+
+// Parse checks a scanned code and returns its session id and window. The
+// caller still has to check the window against the session's rotation with
+// Current, since the rotation is stored with the session.
+func Parse(token string) (sessionID int, window int64, err error) {
+	t, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return config.JWTSecret(), nil
+	}, jwt.WithAudience(audience), jwt.WithExpirationRequired())
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return 0, 0, ErrStale
+	}
+	if err != nil || !t.Valid {
+		return 0, 0, ErrInvalid
+	}
+	claims, ok := t.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, 0, ErrInvalid
+	}
+	sid, ok1 := claims["sid"].(float64)
+	win, ok2 := claims["win"].(float64)
+	if !ok1 || !ok2 {
+		return 0, 0, ErrInvalid
+	}
+	return int(sid), int64(win), nil
+}
+
+// Current reports whether a code from window is still accepted at now.
+func Current(s *models.AttendanceSession, window int64, now time.Time) bool {
+	cur := Window(now, s.RotateSeconds)
+	return window <= cur && window >= cur-Grace
+}
+
+// WriteCSV exports the check-ins of a session with times in loc. Titles and
+// names are typed by users, so they are escaped against spreadsheet formulas.
+func WriteCSV(w io.Writer, s *models.AttendanceSession, checkIns []models.AttendanceCheckIn, loc *time.Location) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"session", "username", "display_name", "checked_in_at"})
+	for _, ci := range checkIns {
+		cw.Write([]string{csvCell(s.Title), csvCell(ci.Username), csvCell(ci.DisplayName), ci.CheckedInAt.In(loc).Format("2006-01-02 15:04:05")})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("write attendance csv: %w", err)
+	}
+	return nil
+}
+
+// csvCell prefixes text that Excel or Sheets would run as a formula with an
+// apostrophe, which they show as plain text.
+func csvCell(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
@@ -0,0 +1,93 @@
+// Package booking turns counselor availability into bookable slots, exports
+// appointments as iCalendar and sends appointment reminders.
+package booking
+
+import (
+	"edu-web-backend/internal/models"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ParseClock parses an "HH:MM" time of day into minutes after midnight.
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Slots expands the weekly schedule into free slots for days dates starting at
+// from (a date in loc). Slots that start before now, fall in an exception or
+// overlap a booked appointment are left out.
+func Slots(weekly []models.Availability, exceptions []models.AvailabilityException, booked []models.Appointment, from time.Time, days int, loc *time.Location, now time.Time) []models.Slot {
+	slots := []models.Slot{}
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, loc)
+	for i := 0; i < days; i++ {
+		date := day.AddDate(0, 0, i)
+		for _, a := range weekly {
+			if time.Weekday(a.Weekday) != date.Weekday() || a.SlotMinutes <= 0 {
+				continue
+			}
+			startMin, err1 := ParseClock(a.StartTime)
+			endMin, err2 := ParseClock(a.EndTime)
+			if err1 != nil || err2 != nil {
+				continue
+			}
+			for m := startMin; m+a.SlotMinutes <= endMin; m += a.SlotMinutes {
+				s := models.Slot{
+					StartsAt: date.Add(time.Duration(m) * time.Minute),
+					EndsAt:   date.Add(time.Duration(m+a.SlotMinutes) * time.Minute),
+				}
+				if !s.StartsAt.After(now) || blocked(s, date, exceptions) || taken(s, booked) {
+					continue
+				}
+				slots = append(slots, s)
+			}
+		}
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].StartsAt.Before(slots[j].StartsAt) })
+	return slots
+}
+
+// FindSlot returns the slot starting exactly at start.
+func FindSlot(slots []models.Slot, start time.Time) (models.Slot, bool) {
+	for _, s := range slots {
+		if s.StartsAt.Equal(start) {
+			return s, true
+		}
+	}
+	return models.Slot{}, false
+}
+
+func blocked(s models.Slot, date time.Time, exceptions []models.AvailabilityException) bool {
+	for _, e := range exceptions {
+		if e.Date != date.Format("2006-01-02") {
+			continue
+		}
+		if e.StartTime == "" || e.EndTime == "" {
+			return true
+		}
+		startMin, err1 := ParseClock(e.StartTime)
+		endMin, err2 := ParseClock(e.EndTime)
+		if err1 != nil || err2 != nil {
+			return true
+		}
+		from := date.Add(time.Duration(startMin) * time.Minute)
+		to := date.Add(time.Duration(endMin) * time.Minute)
+		if s.StartsAt.Before(to) && s.EndsAt.After(from) {
+			return true
+		}
+	}
+	return false
+}
+
+func taken(s models.Slot, booked []models.Appointment) bool {
+	for _, a := range booked {
+		if a.Status == models.AppointmentBooked && s.StartsAt.Before(a.EndsAt) && s.EndsAt.After(a.StartsAt) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+package booking
+
+import (
+	"edu-web-backend/internal/models"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event is one appointment as seen by the person exporting it.
+type Event struct {
+	Appointment models.Appointment
+	Summary     string
+	Description string
+}
+
+// ICS renders events as an iCalendar (RFC 5545) document. Times are written in
+// UTC so calendar apps convert them to the reader's zone.
+func ICS(events []Event, now time.Time) string {
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:-//EduHub//Counseling//VI")
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	for _, e := range events {
+		a := e.Appointment
+		status := "CONFIRMED"
+		if a.Status == models.AppointmentCancelled {
+			status = "CANCELLED"
+		}
+		writeLine(&b, "BEGIN:VEVENT")
+		writeLine(&b, fmt.Sprintf("UID:appointment-%d@eduhub", a.ID))
+		writeLine(&b, "DTSTAMP:"+icsTime(now))
+		writeLine(&b, "DTSTART:"+icsTime(a.StartsAt))
+		writeLine(&b, "DTEND:"+icsTime(a.EndsAt))
+		writeLine(&b, "SUMMARY:"+icsText(e.Summary))
+		if e.Description != "" {
+			writeLine(&b, "DESCRIPTION:"+icsText(e.Description))
+		}
+		writeLine(&b, "STATUS:"+status)
+		writeLine(&b, "END:VEVENT")
+	}
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func icsText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeLine folds content lines longer than 75 octets, as the RFC requires,
+// without splitting a UTF-8 sequence.
+func writeLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	b.WriteString(line + "\r\n")
+}
@@ -0,0 +1,96 @@
+package booking
+
+import (
+	"context"
+	"edu-web-backend/internal/models"
+	"edu-web-backend/internal/notify"
+	"edu-web-backend/internal/repository"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Notification texts. The counselor is always one end of the thread.
+
+func BookedText(a models.Appointment, loc *time.Location) string {
+	text := "[Lich hen] Em da dat lich tu van luc " + formatRange(a, loc) + "."
+	if a.Note != "" {
+		text += "\nGhi chu: " + a.Note
+	}
+	return text
+}
+
+func RescheduledText(a models.Appointment, loc *time.Location) string {
+	return "[Lich hen] Lich tu van da doi sang " + formatRange(a, loc) + "."
+}
+
+func CancelledText(a models.Appointment, loc *time.Location) string {
+	return "[Lich hen] Lich tu van luc " + formatRange(a, loc) + " da bi huy."
+}
+
+func ReminderText(a models.Appointment, loc *time.Location) string {
+	return "[Nhac lich] Ban co buoi tu van luc " + formatRange(a, loc) + ". Neu khong den duoc, hay huy hoac doi lich nhe."
+}
+
+func formatRange(a models.Appointment, loc *time.Location) string {
+	start, end := a.StartsAt.In(loc), a.EndsAt.In(loc)
+	return fmt.Sprintf("%s-%s ngay %s", start.Format("15:04"), end.Format("15:04"), start.Format("02/01/2006"))
+}
+
+// Reminder sends one reminder per booked appointment once it is within lead
+// of its start time.
+type Reminder struct {
+	db       *repository.DB
+	notifier notify.Notifier
+	lead     time.Duration
+	loc      *time.Location
+}
+
+func NewReminder(db *repository.DB, notifier notify.Notifier, lead time.Duration, loc *time.Location) *Reminder {
+	return &Reminder{db: db, notifier: notifier, lead: lead, loc: loc}
+}
+
+// Run sends every due reminder once and returns how many were sent. A
+// reminder that cannot be delivered is logged and retried on the next run,
+// without holding up the rest of the batch.
+func (r *Reminder) Run(ctx context.Context) (int, error) {
+	due, err := r.db.GetDueReminders(ctx, time.Now().Add(r.lead))
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, a := range due {
+		msg := notify.Message{FromUserID: a.CounselorID, ToUserID: a.StudentID, Body: ReminderText(a, r.loc)}
+		if err := r.notifier.Notify(ctx, msg); err != nil {
+			log.Printf("Appointment reminder %d: %v", a.ID, err)
+			continue
+		}
+		if err := r.db.MarkReminderSent(ctx, a.ID); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// Schedule checks for due reminders at startup and then every interval until ctx is done.
+func (r *Reminder) Schedule(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || r.lead <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		n, err := r.Run(ctx)
+		if err != nil {
+			log.Printf("Appointment reminder error: %v", err)
+		} else if n > 0 {
+			log.Printf("Appointment reminders sent: %d", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
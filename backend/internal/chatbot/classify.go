@@ -0,0 +1,156 @@
+// Package chatbot is Buddy's message classifier: the keyword categories and
+// the crisis check that the response engine, screenings and anonymous
+// questions all rely on. It has no database dependency so it can be evaluated
+// offline with cmd/chatbot-eval.
+package chatbot
+
+import (
+	"sort"
+	"strings"
+)
+
+// categoryKeywords maps psychological categories to trigger keywords (no-diacritic Vietnamese + English).
+var categoryKeywords = map[string][]string{
+	"stress": {
+		"stress", "cang thang", "ap luc", "kiem tra", "thi cu", "on thi",
+		"thi dai hoc", "qua tai", "nhieu viec", "met moi", "dau dau",
+		"so sanh", "truot", "bo me ky vong", "lich hoc", "bai kho",
+		"stressed", "pressure", "exam", "overwhelmed", "deadline", "too much homework",
+	},
+	"anxiety": {
+		"lo lang", "anxiety", "lo au", "hoi hop", "so hai",
+		"hoang loan", "panic", "ngu khong duoc", "mat ngu lo",
+		"nguoi khac nghi", "bi phan xet", "bat an", "khong yen",
+		"run", "tim dap nhanh", "kho tho",
+		"anxious", "worried", "worry", "nervous", "panic attack", "scared",
+	},
+	"motivation": {
+		"mat dong luc", "chan hoc", "khong muon hoc", "luoi", "tri hoan",
+		"khong co muc tieu", "vo nghia", "game", "dien tu", "nan long",
+		"bo cuoc", "that bai", "ghen ti", "procrastinat",
+		"unmotivated", "motivation", "lazy", "give up", "pointless",
+	},
+	"focus": {
+		"tap trung", "focus", "phan tam", "mat tap trung", "hay quen",
+		"khong nho", "dien thoai", "mang xa hoi", "facebook", "tiktok",
+		"lan man", "buon ngu khi hoc", "adhd", "tang dong", "khong hoan thanh",
+		"concentrate", "distracted", "can't focus", "social media",
+	},
+	"sleep": {
+		"ngu", "sleep", "mat ngu", "kho ngu", "khong ngu duoc",
+		"buon ngu", "ac mong", "thuc khuya", "day som", "giac ngu",
+		"ngu khong ngon", "nghi nhieu truoc khi ngu",
+		"insomnia", "can't sleep", "nightmare", "stay up late",
+	},
+	"loneliness": {
+		"co don", "le loi", "mot minh", "khong co ban", "ban be",
+		"bi xa lanh", "bi bo roi", "chia tay", "mau thuan", "xung dot",
+		"thay co", "bo me khong hieu", "khong ai hieu", "tinh yeu",
+		"lonely", "alone", "no friends", "left out", "breakup", "broke up", "homesick",
+	},
+	"self-esteem": {
+		"tu ti", "kem coi", "ngoai hinh", "xau", "beo",
+		"gay", "khong gioi", "dot", "vo dung", "khong xung dang",
+		"tu trach", "tu phe binh", "diem thap",
+		"worthless", "not good enough", "ugly", "hate myself", "stupid", "bad grades",
+	},
+	"depression": {
+		"buon", "tram cam", "depression", "sad", "trong rong", "vo cam",
+		"mat hung", "khoc", "tuyet vong", "vo vong", "khong co hy vong",
+		"tu tu", "tu lam hai", "chet", "khong con suc",
+		"depressed", "hopeless", "empty inside", "crying", "numb",
+	},
+}
+
+var categories = func() []string {
+	names := make([]string, 0, len(categoryKeywords))
+	for name := range categoryKeywords {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}()
+
+// Categories returns the category names in alphabetical order.
+func Categories() []string {
+	return categories
+}
+
+func IsCategory(name string) bool {
+	_, ok := categoryKeywords[name]
+	return ok
+}
+
+// DetectCategory scores the message against all category keywords and returns
+// the best match. Ties go to the alphabetically first category so the result
+// does not depend on map iteration order.
+func DetectCategory(msg string) string {
+	msg = Normalize(msg)
+	best, bestScore := "", 0
+	for _, category := range categories {
+		score := 0
+		for _, kw := range categoryKeywords[category] {
+			if strings.Contains(msg, kw) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = category, score
+		}
+	}
+	return best
+}
+
+// emergencyKeywords are self-harm phrases that always take the crisis path.
+var emergencyKeywords = []string{
+	"tu tu", "tu lam hai", "muon chet", "khong muon song", "ket thuc tat ca",
+	"suicide", "suicidal", "kill myself", "want to die", "end my life", "self harm", "self-harm",
+	"hurt myself", "don't want to live", "dont want to live",
+}
+
+func IsCrisis(msg string) bool {
+	msg = Normalize(msg)
+	for _, kw := range emergencyKeywords {
+		if strings.Contains(msg, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// Classification is everything the response engine branches on for a message.
+type Classification struct {
+	Category string
+	Crisis   bool
+}
+
+func Classify(msg string) Classification {
+	return Classification{Category: DetectCategory(msg), Crisis: IsCrisis(msg)}
+}
+
+var diacritics = map[rune]string{
+	'a': "àáảãạăằắẳẵặâầấẩẫậ",
+	'd': "đ",
+	'e': "èéẻẽẹêềếểễệ",
+	'i': "ìíỉĩị",
+	'o': "òóỏõọôồốổỗộơờớởỡợ",
+	'u': "ùúủũụưừứửữự",
+	'y': "ỳýỷỹỵ",
+}
+
+var foldVietnamese = func() *strings.Replacer {
+	pairs := []string{"’", "'"} // phone keyboards type curly apostrophes
+	for base, accented := range diacritics {
+		for _, r := range accented {
+			pairs = append(pairs, string(r), string(base))
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// Normalize lowercases the message and strips Vietnamese diacritics, so
+// "tôi muốn chết" matches the keyword "muon chet". Keywords are written
+// without diacritics.
+func Normalize(msg string) string {
+	return foldVietnamese.Replace(strings.ToLower(msg))
+}
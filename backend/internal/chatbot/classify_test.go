@@ -0,0 +1,41 @@
+package chatbot
+
+import "testing"
+
+// Students type with and without diacritics and phone keyboards send curly
+// apostrophes; keywords are written plain, so both spellings must match.
+func TestNormalizeFoldsDiacritics(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"Tôi MUỐN CHẾT", "toi muon chet"},
+		{"Đường Nguyễn Trãi", "duong nguyen trai"},
+		{"I don’t want to live", "i don't want to live"},
+		{"minh met qua", "minh met qua"},
+	}
+	for _, tc := range cases {
+		if got := Normalize(tc.in); got != tc.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestIsCrisisWithDiacritics(t *testing.T) {
+	for _, msg := range []string{"Tôi không muốn sống nữa", "Mình muốn chết", "I don’t want to live anymore"} {
+		if !IsCrisis(msg) {
+			t.Errorf("IsCrisis(%q) = false", msg)
+		}
+	}
+	if IsCrisis("Mình buồn vì điểm thấp") {
+		t.Error("IsCrisis flagged a non-crisis message")
+	}
+}
+
+// A message scoring the same in two categories must not depend on map
+// iteration order, or the eval baseline and replies change between runs.
+func TestDetectCategoryTieBreak(t *testing.T) {
+	const msg = "minh lo lang va co don"
+	for i := 0; i < 50; i++ {
+		if got := DetectCategory(msg); got != "anxiety" {
+			t.Fatalf("DetectCategory(%q) = %q, want anxiety", msg, got)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package chatbot
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Supported reply languages.
+const (
+	LangVietnamese = "vi"
+	LangEnglish    = "en"
+)
+
+func IsLanguage(lang string) bool {
+	return lang == LangVietnamese || lang == LangEnglish
+}
+
+// englishWords and vietnameseWords are common words. Vietnamese ones are
+// written without diacritics because many students type that way; English
+// words that are also Vietnamese without diacritics ("me", "do", "an") are left out.
+var englishWords = map[string]bool{
+	"i": true, "i'm": true, "im": true, "my": true, "you": true, "the": true,
+	"and": true, "is": true, "are": true, "was": true, "of": true, "that": true,
+	"this": true, "for": true, "with": true, "have": true, "feel": true, "feeling": true,
+	"very": true, "what": true, "how": true, "can't": true, "cant": true, "don't": true,
+	"dont": true, "not": true, "about": true, "hello": true, "hi": true, "hey": true,
+	"help": true, "want": true, "really": true, "just": true, "myself": true,
+}
+
+var vietnameseWords = map[string]bool{
+	"minh": true, "toi": true, "ban": true, "em": true, "la": true, "khong": true,
+	"co": true, "duoc": true, "qua": true, "lam": true, "roi": true, "nhe": true,
+	"thay": true, "cam": true, "va": true, "cua": true, "nay": true, "nhung": true,
+	"voi": true, "gi": true, "sao": true, "vi": true, "de": true, "cho": true,
+	"chao": true, "xin": true, "hoc": true, "bi": true, "ma": true, "nua": true,
+}
+
+// DetectLanguage guesses whether a message is Vietnamese or English. Any
+// Vietnamese diacritic settles it; otherwise common words are counted, and
+// ties (including messages with no known words) default to Vietnamese.
+func DetectLanguage(msg string) string {
+	return DetectLanguageOr(msg, LangVietnamese)
+}
+
+// DetectLanguageOr is DetectLanguage with ties going to fallback, so a short
+// reply such as "suicide" or "ok" keeps the language the conversation is in.
+func DetectLanguageOr(msg, fallback string) string {
+	lower := strings.ReplaceAll(strings.ToLower(msg), "’", "'")
+	if foldVietnamese.Replace(lower) != lower {
+		return LangVietnamese
+	}
+	en, vi := 0, 0
+	for _, w := range strings.FieldsFunc(lower, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	}) {
+		if englishWords[w] {
+			en++
+		}
+		if vietnameseWords[w] {
+			vi++
+		}
+	}
+	switch {
+	case en > vi:
+		return LangEnglish
+	case vi > en:
+		return LangVietnamese
+	}
+	return fallback
+}
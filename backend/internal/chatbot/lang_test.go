@@ -0,0 +1,20 @@
+package chatbot
+
+import "testing"
+
+func TestDetectLanguageOr(t *testing.T) {
+	cases := []struct{ msg, fallback, want string }{
+		{"Mình buồn quá", LangEnglish, LangVietnamese},
+		{"I feel really tired", LangVietnamese, LangEnglish},
+		{"minh met qua", LangEnglish, LangVietnamese},
+		// No known words: keep the conversation's language.
+		{"suicide", LangEnglish, LangEnglish},
+		{"ok", LangEnglish, LangEnglish},
+		{"ok", LangVietnamese, LangVietnamese},
+	}
+	for _, tc := range cases {
+		if got := DetectLanguageOr(tc.msg, tc.fallback); got != tc.want {
+			t.Errorf("DetectLanguageOr(%q, %q) = %q, want %q", tc.msg, tc.fallback, got, tc.want)
+		}
+	}
+}
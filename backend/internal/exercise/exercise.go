@@ -0,0 +1,169 @@
+// Package exercise defines the guided CBT exercises Buddy can walk a student
+// through one step per chat turn.
+package exercise
+
+import "edu-web-backend/internal/chatbot"
+
+type Exercise struct {
+	Code       string   `json:"code"`
+	Title      string   `json:"title"`
+	Intro      string   `json:"intro"`
+	Steps      []string `json:"steps"`
+	Outro      string   `json:"-"`
+	Categories []string `json:"categories"`
+}
+
+// exercises holds the catalogue in each reply language, ordered by
+// preference: ForCategory returns the first match. Every language has the same
+// codes, categories and number of steps, so a session can change language in
+// the middle of an exercise.
+var exercises = map[string][]Exercise{
+	chatbot.LangVietnamese: vietnameseExercises,
+	chatbot.LangEnglish:    englishExercises,
+}
+
+var vietnameseExercises = []Exercise{
+	{
+		Code:  "grounding_54321",
+		Title: "Tiep dat 5-4-3-2-1",
+		Intro: "Bai tap nay giup keo ban ve hien tai khi lo au dang dang len. Minh se hoi tung buoc, ban cu tra loi that ngan nhe. Go 'dung' bat cu luc nao de thoat.",
+		Steps: []string{
+			"Nhin quanh ban va ke ra 5 thu ban dang NHIN thay.",
+			"Bay gio, 4 thu ban co the CHAM vao (ao, ban, tay ghe...). Hay de y cam giac cua tung thu.",
+			"3 am thanh ban dang NGHE thay, du la rat nho.",
+			"2 mui huong ban NGUI thay (hoac 2 mui ban thich neu xung quanh khong co mui gi).",
+			"1 vi ban dang NEM thay, hoac 1 dieu tot ve ban than ban.",
+		},
+		Outro:      "Ban da hoan thanh bai tap tiep dat! Hay hit mot hoi that sau. Ban thay co the minh bay gio the nao?",
+		Categories: []string{"anxiety", "loneliness"},
+	},
+	{
+		Code:  "box_breathing",
+		Title: "Tho hop 4-4-4-4",
+		Intro: "Tho hop giup he than kinh diu lai chi trong vai phut. Ngoi thang lung, tha long vai. Go 'tiep' sau moi buoc, hoac 'dung' de thoat.",
+		Steps: []string{
+			"Hit vao tu tu bang mui trong 4 giay: 1... 2... 3... 4.",
+			"Giu hoi trong 4 giay: 1... 2... 3... 4.",
+			"Tho ra tu tu bang mieng trong 4 giay: 1... 2... 3... 4.",
+			"Giu phoi rong trong 4 giay: 1... 2... 3... 4. Lap lai ca chu ky 4 lan theo nhip cua ban.",
+		},
+		Outro:      "Tuyet voi! Ban vua hoan thanh bai tap tho hop. Ban co the dung no truoc gio kiem tra hoac truoc khi ngu.",
+		Categories: []string{"stress", "sleep"},
+	},
+	{
+		Code:  "thought_record",
+		Title: "Ghi chep suy nghi",
+		Intro: "Ghi chep suy nghi giup ban nhin lai nhung y nghi tieu cuc mot cach cong bang hon. Minh se hoi 6 cau ngan. Go 'dung' de thoat.",
+		Steps: []string{
+			"Tinh huong: chuyen gi da xay ra, o dau, khi nao?",
+			"Cam xuc: luc do ban cam thay gi, va manh den muc nao (0-100)?",
+			"Suy nghi tu dong: cau nao hien len trong dau ban luc do?",
+			"Bang chung: dieu gi ung ho suy nghi do, va dieu gi chong lai no?",
+			"Suy nghi can bang: neu ban than cua ban gap chuyen nay, ban se noi gi voi ho?",
+			"Bay gio cam xuc ban ban dau con manh den muc nao (0-100)?",
+		},
+		Outro:      "Cam on ban da kien nhan lam het bai ghi chep! Chi viec nhin lai suy nghi cua minh da la mot buoc rat lon.",
+		Categories: []string{"depression", "self-esteem", "motivation"},
+	},
+	{
+		Code:  "worry_timebox",
+		Title: "Hop thoi gian lo lang",
+		Intro: "Thay vi lo lang ca ngay, ta se 'hen' no vao mot khung gio co dinh. Minh hoi 4 buoc nhe, go 'dung' de thoat.",
+		Steps: []string{
+			"Viet ra tat ca nhung dieu ban dang lo lang, moi dieu mot dong.",
+			"Voi moi dieu, no co nam trong tam kiem soat cua ban khong?",
+			"Chon mot viec ban kiem soat duoc va viet buoc nho dau tien ban se lam.",
+			"Chon 15 phut moi ngay lam 'gio lo lang' (vi du 17h00). Ngoai gio do, khi lo lang xuat hien, hay ghi lai va de danh cho gio lo lang. Ban chon gio nao?",
+		},
+		Outro:      "Ban da lap xong hop thoi gian lo lang! Hay thu giu lich nay trong mot tuan va xem giac ngu, su tap trung cua ban thay doi the nao.",
+		Categories: []string{"stress", "anxiety", "sleep", "focus"},
+	},
+}
+
+var englishExercises = []Exercise{
+	{
+		Code:  "grounding_54321",
+		Title: "5-4-3-2-1 grounding",
+		Intro: "This exercise brings you back to the present when anxiety is rising. I'll ask one step at a time; just answer briefly. Type 'stop' at any time to leave.",
+		Steps: []string{
+			"Look around and name 5 things you can SEE.",
+			"Now 4 things you can TOUCH (your sleeve, the desk, the chair...). Notice how each one feels.",
+			"3 sounds you can HEAR, however quiet.",
+			"2 things you can SMELL (or 2 smells you like if there is nothing around).",
+			"1 thing you can TASTE, or 1 good thing about yourself.",
+		},
+		Outro:      "You finished the grounding exercise! Take one slow, deep breath. How do you feel right now?",
+		Categories: []string{"anxiety", "loneliness"},
+	},
+	{
+		Code:  "box_breathing",
+		Title: "Box breathing 4-4-4-4",
+		Intro: "Box breathing calms your nervous system in just a few minutes. Sit up straight and relax your shoulders. Type 'next' after each step, or 'stop' to leave.",
+		Steps: []string{
+			"Breathe in slowly through your nose for 4 seconds: 1... 2... 3... 4.",
+			"Hold your breath for 4 seconds: 1... 2... 3... 4.",
+			"Breathe out slowly through your mouth for 4 seconds: 1... 2... 3... 4.",
+			"Keep your lungs empty for 4 seconds: 1... 2... 3... 4. Repeat the whole cycle 4 times at your own pace.",
+		},
+		Outro:      "Well done! You finished box breathing. You can use it before a test or before going to sleep.",
+		Categories: []string{"stress", "sleep"},
+	},
+	{
+		Code:  "thought_record",
+		Title: "Thought record",
+		Intro: "A thought record helps you look at negative thoughts more fairly. I'll ask 6 short questions. Type 'stop' to leave.",
+		Steps: []string{
+			"Situation: what happened, where and when?",
+			"Feelings: what did you feel, and how strongly (0-100)?",
+			"Automatic thought: what went through your mind at that moment?",
+			"Evidence: what supports that thought, and what goes against it?",
+			"Balanced thought: if a friend were in this situation, what would you tell them?",
+			"How strong is the feeling from the start now (0-100)?",
+		},
+		Outro:      "Thank you for working through the whole thought record! Just looking at your thoughts again is a big step.",
+		Categories: []string{"depression", "self-esteem", "motivation"},
+	},
+	{
+		Code:  "worry_timebox",
+		Title: "Worry time",
+		Intro: "Instead of worrying all day, we'll give worry a fixed appointment. I'll ask 4 steps; type 'stop' to leave.",
+		Steps: []string{
+			"Write down everything you are worried about, one per line.",
+			"For each one, is it within your control?",
+			"Pick one thing you can control and write the first small step you will take.",
+			"Choose 15 minutes each day as your 'worry time' (for example 5:00 pm). Outside that time, when a worry comes up, note it down and save it for worry time. Which time do you choose?",
+		},
+		Outro:      "Your worry time is set! Try keeping it for a week and see how your sleep and focus change.",
+		Categories: []string{"stress", "anxiety", "sleep", "focus"},
+	},
+}
+
+// List returns every exercise in lang, or in Vietnamese for an unknown lang.
+func List(lang string) []Exercise {
+	if list, ok := exercises[lang]; ok {
+		return list
+	}
+	return exercises[chatbot.LangVietnamese]
+}
+
+// Get returns the exercise with the given code in lang.
+func Get(code, lang string) (Exercise, bool) {
+	for _, e := range List(lang) {
+		if e.Code == code {
+			return e, true
+		}
+	}
+	return Exercise{}, false
+}
+
+// ForCategory returns the preferred exercise for a detected chatbot category.
+func ForCategory(category, lang string) (Exercise, bool) {
+	for _, e := range List(lang) {
+		for _, c := range e.Categories {
+			if c == category {
+				return e, true
+			}
+		}
+	}
+	return Exercise{}, false
+}
@@ -0,0 +1,498 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"edu-web-backend/internal/booking"
+	"edu-web-backend/internal/models"
+	"edu-web-backend/internal/notify"
+	"edu-web-backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxAvailabilityBlocks = 50
+	maxSlotDays           = 31
+)
+
+func (h *Handler) ListCounselors(c *gin.Context) {
+	counselors, err := h.db.ListCounselors(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch counselors"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": counselors, "total": len(counselors)})
+}
+
+// GetCounselorSlots lists free slots for ?from=YYYY-MM-DD (default today) and
+// the following ?days= days (default 7).
+func (h *Handler) GetCounselorSlots(c *gin.Context) {
+	counselorID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || counselorID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	days, err := strconv.Atoi(c.DefaultQuery("days", "7"))
+	if err != nil || days <= 0 || days > maxSlotDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days must be between 1 and %d", maxSlotDays)})
+		return
+	}
+	from := time.Now().In(h.loc)
+	if v := c.Query("from"); v != "" {
+		from, err = time.ParseInLocation("2006-01-02", v, h.loc)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be YYYY-MM-DD"})
+			return
+		}
+	}
+
+	counselor, err := h.db.GetUserByID(c.Request.Context(), counselorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	if counselor == nil || counselor.Role != models.RoleCounselor {
+		c.JSON(http.StatusNotFound, gin.H{"error": "counselor not found"})
+		return
+	}
+
+	slots, err := h.freeSlots(c.Request.Context(), counselorID, from, days, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute slots"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": slots, "total": len(slots), "timezone": h.loc.String()})
+}
+
+// freeSlots loads everything booking.Slots needs for the date range.
+// excludeID leaves out the appointment being rescheduled, so its own time
+// does not count as busy.
+func (h *Handler) freeSlots(ctx context.Context, counselorID int, from time.Time, days, excludeID int) ([]models.Slot, error) {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, h.loc)
+	to := from.AddDate(0, 0, days)
+
+	weekly, err := h.db.GetAvailability(ctx, counselorID)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := h.db.GetAvailabilityExceptions(ctx, counselorID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	booked, err := h.db.GetBookedAppointments(ctx, counselorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if excludeID > 0 {
+		kept := booked[:0]
+		for _, a := range booked {
+			if a.ID != excludeID {
+				kept = append(kept, a)
+			}
+		}
+		booked = kept
+	}
+	return booking.Slots(weekly, exceptions, booked, from, days, h.loc, time.Now()), nil
+}
+
+// slotAt returns the free slot of the counselor starting exactly at start,
+// ignoring the appointment excludeID.
+func (h *Handler) slotAt(ctx context.Context, counselorID int, start time.Time, excludeID int) (models.Slot, bool, error) {
+	slots, err := h.freeSlots(ctx, counselorID, start.In(h.loc), 1, excludeID)
+	if err != nil {
+		return models.Slot{}, false, err
+	}
+	slot, ok := booking.FindSlot(slots, start)
+	return slot, ok, nil
+}
+
+func (h *Handler) BookAppointment(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req struct {
+		CounselorID int       `json:"counselor_id" binding:"required"`
+		StartsAt    time.Time `json:"starts_at" binding:"required"`
+		Note        string    `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if len(req.Note) > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "note too long (max 1000)"})
+		return
+	}
+	if req.CounselorID == userID.(int) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "cannot book an appointment with yourself"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	counselor, err := h.db.GetUserByID(ctx, req.CounselorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	if counselor == nil || counselor.Role != models.RoleCounselor {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "counselor not found"})
+		return
+	}
+
+	slot, ok, err := h.slotAt(ctx, req.CounselorID, req.StartsAt, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute slots"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusConflict, gin.H{"error": "slot is not available"})
+		return
+	}
+
+	// Notes can carry personal details just like chat messages.
+	note, _ := h.redactor.Redact(req.Note)
+	appt := &models.Appointment{
+		CounselorID: req.CounselorID,
+		StudentID:   userID.(int),
+		StartsAt:    slot.StartsAt,
+		EndsAt:      slot.EndsAt,
+		Note:        note,
+	}
+	if err := h.db.BookAppointment(ctx, appt); err != nil {
+		if errors.Is(err, repository.ErrSlotTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": "slot is already booked"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to book appointment"})
+		return
+	}
+
+	h.notifyAppointment(ctx, *appt, appt.StudentID, booking.BookedText(*appt, h.loc))
+	c.JSON(http.StatusCreated, gin.H{"data": appt})
+}
+
+// GetAppointments lists the caller's appointments as student or counselor.
+// ?upcoming=false includes past and cancelled ones.
+func (h *Handler) GetAppointments(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	appts, err := h.db.ListAppointments(c.Request.Context(), userID.(int), c.DefaultQuery("upcoming", "true") != "false")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch appointments"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": appts, "total": len(appts)})
+}
+
+func (h *Handler) CancelAppointment(c *gin.Context) {
+	appt, userID, ok := h.participantAppointment(c)
+	if !ok {
+		return
+	}
+	cancelled, err := h.db.CancelAppointment(c.Request.Context(), appt.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel appointment"})
+		return
+	}
+	if cancelled == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "appointment is not booked"})
+		return
+	}
+	h.notifyAppointment(c.Request.Context(), *cancelled, userID, booking.CancelledText(*cancelled, h.loc))
+	c.JSON(http.StatusOK, gin.H{"data": cancelled})
+}
+
+func (h *Handler) RescheduleAppointment(c *gin.Context) {
+	appt, userID, ok := h.participantAppointment(c)
+	if !ok {
+		return
+	}
+	if appt.Status != models.AppointmentBooked {
+		c.JSON(http.StatusConflict, gin.H{"error": "appointment is not booked"})
+		return
+	}
+
+	var req struct {
+		StartsAt time.Time `json:"starts_at" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	slot, free, err := h.slotAt(ctx, appt.CounselorID, req.StartsAt, appt.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to compute slots"})
+		return
+	}
+	if !free {
+		c.JSON(http.StatusConflict, gin.H{"error": "slot is not available"})
+		return
+	}
+	if err := h.db.RescheduleAppointment(ctx, appt, slot.StartsAt, slot.EndsAt); err != nil {
+		if errors.Is(err, repository.ErrSlotTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": "slot is already booked"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to reschedule appointment"})
+		return
+	}
+	h.notifyAppointment(ctx, *appt, userID, booking.RescheduledText(*appt, h.loc))
+	c.JSON(http.StatusOK, gin.H{"data": appt})
+}
+
+// ExportAppointmentICS downloads one appointment as an .ics file.
+func (h *Handler) ExportAppointmentICS(c *gin.Context) {
+	appt, userID, ok := h.participantAppointment(c)
+	if !ok {
+		return
+	}
+	h.writeICS(c, userID, []models.Appointment{*appt}, fmt.Sprintf("appointment-%d.ics", appt.ID))
+}
+
+// ExportAppointmentsICS downloads all upcoming appointments as one calendar.
+func (h *Handler) ExportAppointmentsICS(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	appts, err := h.db.ListAppointments(c.Request.Context(), userID.(int), true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch appointments"})
+		return
+	}
+	h.writeICS(c, userID.(int), appts, "appointments.ics")
+}
+
+func (h *Handler) writeICS(c *gin.Context, userID int, appts []models.Appointment, filename string) {
+	names := map[int]string{}
+	events := make([]booking.Event, 0, len(appts))
+	for _, a := range appts {
+		otherID := a.CounselorID
+		if otherID == userID {
+			otherID = a.StudentID
+		}
+		name, ok := names[otherID]
+		if !ok {
+			u, err := h.db.GetUserByID(c.Request.Context(), otherID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+				return
+			}
+			if u != nil {
+				name = u.DisplayName
+			}
+			names[otherID] = name
+		}
+		events = append(events, booking.Event{Appointment: a, Summary: "Tu van voi " + name, Description: a.Note})
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(booking.ICS(events, time.Now())))
+}
+
+// participantAppointment loads :id and makes sure the caller is its student or
+// counselor. It writes the error response and returns ok=false otherwise.
+func (h *Handler) participantAppointment(c *gin.Context) (*models.Appointment, int, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return nil, 0, false
+	}
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil, 0, false
+	}
+	appt, err := h.db.GetAppointment(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return nil, 0, false
+	}
+	if appt == nil || (appt.StudentID != userID.(int) && appt.CounselorID != userID.(int)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "appointment not found"})
+		return nil, 0, false
+	}
+	return appt, userID.(int), true
+}
+
+// notifyAppointment messages the other participant in the student-counselor
+// thread. A failed notification does not undo the booking.
+func (h *Handler) notifyAppointment(ctx context.Context, a models.Appointment, fromID int, body string) {
+	toID := a.CounselorID
+	if fromID == a.CounselorID {
+		toID = a.StudentID
+	}
+	if err := h.notifier.Notify(ctx, notify.Message{FromUserID: fromID, ToUserID: toID, Body: body}); err != nil {
+		log.Printf("appointment %d notification error: %v", a.ID, err)
+	}
+}
+
+func (h *Handler) GetAvailability(c *gin.Context) {
+	counselorID, _ := c.Get("user_id")
+	weekly, err := h.db.GetAvailability(c.Request.Context(), counselorID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch availability"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": weekly, "total": len(weekly), "timezone": h.loc.String()})
+}
+
+// SetAvailability replaces the caller's weekly schedule. Existing bookings are
+// kept even if they no longer fall inside it.
+func (h *Handler) SetAvailability(c *gin.Context) {
+	counselorID, _ := c.Get("user_id")
+	var req struct {
+		Slots []models.Availability `json:"slots"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Slots) > maxAvailabilityBlocks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d availability blocks", maxAvailabilityBlocks)})
+		return
+	}
+	if err := validateAvailability(req.Slots); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.db.ReplaceAvailability(c.Request.Context(), counselorID.(int), req.Slots); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save availability"})
+		return
+	}
+	h.GetAvailability(c)
+}
+
+// validateAvailability checks each block and rejects overlapping blocks on
+// the same weekday, which would produce duplicate slots.
+func validateAvailability(blocks []models.Availability) error {
+	type span struct{ start, end int }
+	byDay := map[int][]span{}
+	for i := range blocks {
+		a := &blocks[i]
+		if a.Weekday < 0 || a.Weekday > 6 {
+			return fmt.Errorf("weekday must be 0 (Sunday) to 6")
+		}
+		if a.SlotMinutes == 0 {
+			a.SlotMinutes = 30
+		}
+		if a.SlotMinutes < 10 || a.SlotMinutes > 240 {
+			return fmt.Errorf("slot_minutes must be between 10 and 240")
+		}
+		start, err := booking.ParseClock(a.StartTime)
+		if err != nil {
+			return err
+		}
+		end, err := booking.ParseClock(a.EndTime)
+		if err != nil {
+			return err
+		}
+		if end-start < a.SlotMinutes {
+			return fmt.Errorf("%s-%s is shorter than one slot", a.StartTime, a.EndTime)
+		}
+		for _, s := range byDay[a.Weekday] {
+			if start < s.end && end > s.start {
+				return fmt.Errorf("overlapping availability on weekday %d", a.Weekday)
+			}
+		}
+		byDay[a.Weekday] = append(byDay[a.Weekday], span{start, end})
+	}
+	return nil
+}
+
+// GetAvailabilityExceptions lists exceptions between ?from= and ?to= (default: the next 90 days).
+func (h *Handler) GetAvailabilityExceptions(c *gin.Context) {
+	counselorID, _ := c.Get("user_id")
+	today := time.Now().In(h.loc)
+	from := c.DefaultQuery("from", today.Format("2006-01-02"))
+	to := c.DefaultQuery("to", today.AddDate(0, 0, 90).Format("2006-01-02"))
+	for _, d := range []string{from, to} {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dates must be YYYY-MM-DD"})
+			return
+		}
+	}
+	exceptions, err := h.db.GetAvailabilityExceptions(c.Request.Context(), counselorID.(int), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch exceptions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": exceptions, "total": len(exceptions)})
+}
+
+// CreateAvailabilityException blocks a whole date, or a time range on it when
+// start_time and end_time are given.
+func (h *Handler) CreateAvailabilityException(c *gin.Context) {
+	counselorID, _ := c.Get("user_id")
+	var req models.AvailabilityException
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date must be YYYY-MM-DD"})
+		return
+	}
+	if (req.StartTime == "") != (req.EndTime == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_time and end_time must be given together"})
+		return
+	}
+	if req.StartTime != "" {
+		start, err1 := booking.ParseClock(req.StartTime)
+		end, err2 := booking.ParseClock(req.EndTime)
+		if err1 != nil || err2 != nil || end <= start {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid time range, expected HH:MM with end after start"})
+			return
+		}
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if len(req.Reason) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason too long (max 200)"})
+		return
+	}
+
+	req.CounselorID = counselorID.(int)
+	if err := h.db.CreateAvailabilityException(c.Request.Context(), &req); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save exception"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": req})
+}
+
+func (h *Handler) DeleteAvailabilityException(c *gin.Context) {
+	counselorID, _ := c.Get("user_id")
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	found, err := h.db.DeleteAvailabilityException(c.Request.Context(), id, counselorID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete exception"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "exception not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "exception deleted"})
+}
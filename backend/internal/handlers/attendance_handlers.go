@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"edu-web-backend/internal/attendance"
+	"edu-web-backend/internal/models"
+	"edu-web-backend/internal/qr"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRotateSeconds  = 15
+	defaultAttendanceMins = 10
+	maxAttendanceMins     = 240
+	minRotateSeconds      = 5
+	maxRotateSeconds      = 120
+	checkInPagePath       = "/attendance/check-in"
+)
+
+// StartAttendance opens a check-in session. The teacher then shows its
+// rotating code (GET .../code) until the session ends.
+func (h *Handler) StartAttendance(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	var req struct {
+		Title           string `json:"title" binding:"required"`
+		DurationMinutes int    `json:"duration_minutes"`
+		RotateSeconds   int    `json:"rotate_seconds"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" || len(req.Title) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title must be 1-200 characters"})
+		return
+	}
+	if req.DurationMinutes == 0 {
+		req.DurationMinutes = defaultAttendanceMins
+	}
+	if req.RotateSeconds == 0 {
+		req.RotateSeconds = defaultRotateSeconds
+	}
+	if req.DurationMinutes < 1 || req.DurationMinutes > maxAttendanceMins {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("duration_minutes must be between 1 and %d", maxAttendanceMins)})
+		return
+	}
+	if req.RotateSeconds < minRotateSeconds || req.RotateSeconds > maxRotateSeconds {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("rotate_seconds must be between %d and %d", minRotateSeconds, maxRotateSeconds)})
+		return
+	}
+
+	now := time.Now()
+	session := &models.AttendanceSession{
+		TeacherID:     userID.(int),
+		Title:         req.Title,
+		RotateSeconds: req.RotateSeconds,
+		StartedAt:     now,
+		EndsAt:        now.Add(time.Duration(req.DurationMinutes) * time.Minute),
+	}
+	if err := h.db.CreateAttendanceSession(c.Request.Context(), session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to start session"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": session})
+}
+
+// GetAttendanceSessions lists the caller's sessions; admins see everyone's.
+func (h *Handler) GetAttendanceSessions(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	teacherID := userID.(int)
+	if role, _ := c.Get("user_role"); role == models.RoleAdmin {
+		teacherID = 0
+	}
+	sessions, err := h.db.ListAttendanceSessions(c.Request.Context(), teacherID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch sessions"})
+		return
+	}
+	if sessions == nil {
+		sessions = []models.AttendanceSession{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sessions, "total": len(sessions)})
+}
+
+// GetAttendanceCode returns the check-in link currently shown for a session
+// and how long until it changes, so the teacher's screen knows when to
+// reload the image.
+func (h *Handler) GetAttendanceCode(c *gin.Context) {
+	session := h.loadOwnAttendanceSession(c)
+	if session == nil {
+		return
+	}
+	now := time.Now()
+	if !session.Open(now) {
+		c.JSON(http.StatusGone, gin.H{"error": "session has ended"})
+		return
+	}
+	link, err := h.attendanceLink(session, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to sign code"})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"url":        link,
+		"window":     attendance.Window(now, session.RotateSeconds),
+		"refresh_in": attendance.RefreshIn(now, session.RotateSeconds).Seconds(),
+		"check_ins":  session.CheckIns,
+		"ends_at":    session.EndsAt,
+	}})
+}
+
+// GetAttendanceCodeImage renders the current code (?format=svg by default,
+// ?size=). It is never cached since it changes every few seconds.
+func (h *Handler) GetAttendanceCodeImage(c *gin.Context) {
+	session := h.loadOwnAttendanceSession(c)
+	if session == nil {
+		return
+	}
+	now := time.Now()
+	if !session.Open(now) {
+		c.JSON(http.StatusGone, gin.H{"error": "session has ended"})
+		return
+	}
+	opts := qr.DefaultOptions()
+	opts.Format = qr.FormatSVG
+	if v := c.Query("format"); v != "" {
+		opts.Format = strings.ToLower(v)
+	}
+	if v := c.Query("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "size must be an integer"})
+			return
+		}
+		opts.Size = n
+	}
+	if err := opts.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	link, err := h.attendanceLink(session, now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to sign code"})
+		return
+	}
+	img, err := qr.Render(link, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, qr.ContentType(opts.Format), img)
+}
+
+// EndAttendance closes a session before its scheduled end.
+func (h *Handler) EndAttendance(c *gin.Context) {
+	session := h.loadOwnAttendanceSession(c)
+	if session == nil {
+		return
+	}
+	if err := h.db.EndAttendanceSession(c.Request.Context(), session.ID, time.Now()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to end session"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "session ended"})
+}
+
+// GetAttendanceCheckIns lists who checked in to a session of the caller, in
+// check-in order.
+func (h *Handler) GetAttendanceCheckIns(c *gin.Context) {
+	session := h.loadOwnAttendanceSession(c)
+	if session == nil {
+		return
+	}
+	checkIns, err := h.db.ListCheckIns(c.Request.Context(), session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch check-ins"})
+		return
+	}
+	if checkIns == nil {
+		checkIns = []models.AttendanceCheckIn{}
+	}
+	c.JSON(http.StatusOK, gin.H{"data": checkIns, "total": len(checkIns)})
+}
+
+// ExportAttendanceCSV downloads the check-ins of a session as CSV with
+// times in school time.
+func (h *Handler) ExportAttendanceCSV(c *gin.Context) {
+	session := h.loadOwnAttendanceSession(c)
+	if session == nil {
+		return
+	}
+	checkIns, err := h.db.ListCheckIns(c.Request.Context(), session.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch check-ins"})
+		return
+	}
+	var buf bytes.Buffer
+	if err := attendance.WriteCSV(&buf, session, checkIns, h.loc); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export"})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="attendance-%d.csv"`, session.ID))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// CheckIn records the logged-in student in the session named by a scanned
+// code. Checking in twice keeps the first time.
+func (h *Handler) CheckIn(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sessionID, window, err := attendance.Parse(req.Token)
+	if errors.Is(err, attendance.ErrStale) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	session, err := h.db.GetAttendanceSession(c.Request.Context(), sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return
+	}
+	now := time.Now()
+	if !session.Open(now) {
+		c.JSON(http.StatusGone, gin.H{"error": "session has ended"})
+		return
+	}
+	if !attendance.Current(session, window, now) {
+		c.JSON(http.StatusConflict, gin.H{"error": attendance.ErrStale.Error()})
+		return
+	}
+
+	created, err := h.db.RecordCheckIn(c.Request.Context(), session.ID, userID.(int), now)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to check in"})
+		return
+	}
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{"data": gin.H{
+		"session_id":      session.ID,
+		"title":           session.Title,
+		"already_checked": !created,
+	}})
+}
+
+// attendanceLink is what the rotating code encodes: the web app's check-in
+// page with the signed token.
+func (h *Handler) attendanceLink(session *models.AttendanceSession, now time.Time) (string, error) {
+	token, err := attendance.Token(session, now)
+	if err != nil {
+		return "", err
+	}
+	return h.frontendURL + checkInPagePath + "?t=" + url.QueryEscape(token), nil
+}
+
+// loadOwnAttendanceSession loads the session in the URL for its teacher or
+// an admin, writing the error response and returning nil otherwise.
+func (h *Handler) loadOwnAttendanceSession(c *gin.Context) *models.AttendanceSession {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil
+	}
+	session, err := h.db.GetAttendanceSession(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return nil
+	}
+	userID, _ := c.Get("user_id")
+	role, _ := c.Get("user_role")
+	if session == nil || (session.TeacherID != userID.(int) && role != models.RoleAdmin) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return nil
+	}
+	return session
+}
@@ -106,6 +106,7 @@ func (h *Handler) Register(c *gin.Context) {
 			"username":     user.Username,
 			"email":        user.Email,
 			"display_name": user.DisplayName,
+			"role":         user.Role,
 		},
 	})
 }
@@ -157,6 +158,7 @@ func (h *Handler) Login(c *gin.Context) {
 			"username":     user.Username,
 			"email":        user.Email,
 			"display_name": user.DisplayName,
+			"role":         user.Role,
 		},
 	})
 }
@@ -191,6 +193,38 @@ func (h *Handler) GetMe(c *gin.Context) {
 			"username":     user.Username,
 			"email":        user.Email,
 			"display_name": user.DisplayName,
+			"role":         user.Role,
 		},
 	})
 }
+
+// RequireRole only lets through users whose role is one of roles. It must run
+// after middleware.AuthRequired. The role is read from the database so a role
+// change applies without logging in again.
+func (h *Handler) RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			c.Abort()
+			return
+		}
+		user, err := h.db.GetUserByID(c.Request.Context(), userID.(int))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+			c.Abort()
+			return
+		}
+		if user != nil {
+			for _, r := range roles {
+				if user.Role == r {
+					c.Set("user_role", user.Role)
+					c.Next()
+					return
+				}
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+		c.Abort()
+	}
+}
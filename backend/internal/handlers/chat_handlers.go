@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"time"
+
+	"edu-web-backend/internal/config"
+	"edu-web-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const anonChatCookie = "eduhub_chat_anon"
+
+// anonCookieMaxAge keeps guest sessions reachable for 30 days.
+const anonCookieMaxAge = 30 * 24 * 60 * 60
+
+// chatOwner identifies who is talking to Buddy: the logged-in user (set by
+// OptionalAuth) or the anonymous ID stored in a signed cookie. Either may be empty.
+func chatOwner(c *gin.Context) (userID int, anonID string) {
+	if v, ok := c.Get("user_id"); ok {
+		userID = v.(int)
+	}
+	if cookie, err := c.Cookie(anonChatCookie); err == nil && cookie != "" {
+		anonID = parseAnonToken(cookie)
+	}
+	return userID, anonID
+}
+
+// ensureChatOwner is chatOwner for requests that create sessions: guests without
+// a valid cookie are issued a fresh anonymous ID.
+func ensureChatOwner(c *gin.Context) (userID int, anonID string, err error) {
+	userID, anonID = chatOwner(c)
+	if userID > 0 || anonID != "" {
+		return userID, anonID, nil
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return 0, "", err
+	}
+	anonID = hex.EncodeToString(buf)
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"anon_id": anonID,
+		"exp":     time.Now().Add(anonCookieMaxAge * time.Second).Unix(),
+	}).SignedString(config.JWTSecret())
+	if err != nil {
+		return 0, "", err
+	}
+	secure := os.Getenv("ENV") == "production"
+	c.SetCookie(anonChatCookie, token, anonCookieMaxAge, "/", "", secure, true)
+	return 0, anonID, nil
+}
+
+func parseAnonToken(tokenStr string) string {
+	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
+		}
+		return config.JWTSecret(), nil
+	})
+	if err != nil || !token.Valid {
+		return ""
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	anonID, _ := claims["anon_id"].(string)
+	return anonID
+}
+
+// ownedSession loads the session named in the URL and writes a 404 if the caller
+// does not own it, so other people's session IDs are indistinguishable from
+// nonexistent ones.
+func (h *Handler) ownedSession(c *gin.Context, sessionID string) *models.ChatSession {
+	userID, anonID := chatOwner(c)
+	if userID == 0 && anonID == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return nil
+	}
+	session, err := h.db.GetOwnedChatSession(c.Request.Context(), sessionID, userID, anonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return nil
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		return nil
+	}
+	return session
+}
+
+func (h *Handler) CreateChatSession(c *gin.Context) {
+	userID, anonID, err := ensureChatOwner(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create session"})
+		return
+	}
+	session, err := h.db.CreateChatSession(c.Request.Context(), userID, anonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create session"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": session})
+}
+
+func (h *Handler) ListChatSessions(c *gin.Context) {
+	userID, anonID := chatOwner(c)
+	if userID == 0 && anonID == "" {
+		c.JSON(http.StatusOK, gin.H{"data": []models.ChatSession{}, "total": 0})
+		return
+	}
+	sessions, err := h.db.ListChatSessions(c.Request.Context(), userID, anonID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": sessions, "total": len(sessions)})
+}
+
+func (h *Handler) DeleteChatSession(c *gin.Context) {
+	session := h.ownedSession(c, c.Param("session_id"))
+	if session == nil {
+		return
+	}
+	if err := h.db.DeleteChatSession(c.Request.Context(), session.SessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete session"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "session deleted"})
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"edu-web-backend/internal/chatbot"
+	"edu-web-backend/internal/exercise"
+	"edu-web-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Exercise states. Finished states stay in the table so Buddy does not offer
+// another exercise in the same session.
+const (
+	exerciseOffered   = "offered"
+	exerciseActive    = "active"
+	exerciseDeclined  = "declined"
+	exerciseCompleted = "completed"
+	exerciseCancelled = "cancelled"
+)
+
+var exerciseAcceptWords = []string{"co", "có", "ok", "oke", "okay", "yes", "sure", "dong y", "đồng ý", "bat dau", "bắt đầu", "thu", "thử", "let's start"}
+
+var exerciseStopWords = []string{"dung", "dừng", "thoat", "thoát", "huy", "hủy", "stop", "quit", "cancel", "thoi", "thôi"}
+
+// ListExercises returns the catalogue in ?lang= (vi or en, default vi).
+func (h *Handler) ListExercises(c *gin.Context) {
+	lang := c.DefaultQuery("lang", chatbot.LangVietnamese)
+	if !chatbot.IsLanguage(lang) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lang must be vi or en"})
+		return
+	}
+	list := exercise.List(lang)
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": len(list)})
+}
+
+// exerciseTurn handles a chat turn that belongs to a guided exercise: an explicit
+// start request, accepting an offer, or the next step of an active exercise.
+// handled is false when the message should go through buildAIResponse instead.
+// Exercise texts are in lang, the reply language of this turn.
+func (h *Handler) exerciseTurn(ctx context.Context, sessionID string, userID int, message, requested, lang string) (reply string, state *models.ExerciseState, handled bool, err error) {
+	current, err := h.db.GetExerciseState(ctx, sessionID)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	// A crisis always interrupts the exercise and goes to the crisis path.
+	if chatbot.IsCrisis(message) {
+		if current != nil && current.Status == exerciseActive {
+			current.Status = exerciseCancelled
+			if err := h.finishExercise(ctx, current, userID); err != nil {
+				return "", nil, false, err
+			}
+		}
+		return "", nil, false, nil
+	}
+
+	if requested != "" {
+		return h.startExercise(ctx, sessionID, requested, lang)
+	}
+	if current == nil {
+		return "", nil, false, nil
+	}
+
+	ex, ok := exercise.Get(current.Exercise, lang)
+	if !ok {
+		return "", nil, false, nil
+	}
+
+	switch current.Status {
+	case exerciseOffered:
+		if matchesWord(message, exerciseAcceptWords, 5) {
+			return h.startExercise(ctx, sessionID, ex.Code, lang)
+		}
+		current.Status = exerciseDeclined
+		return "", nil, false, h.db.SaveExerciseState(ctx, current)
+
+	case exerciseActive:
+		if matchesWord(message, exerciseStopWords, 3) {
+			current.Status = exerciseCancelled
+			if err := h.finishExercise(ctx, current, userID); err != nil {
+				return "", nil, false, err
+			}
+			return fmt.Sprintf(localeFor(lang).exerciseStopped, ex.Title), current, true, nil
+		}
+
+		current.Step++
+		if current.Step >= len(ex.Steps) {
+			current.Status = exerciseCompleted
+			if err := h.finishExercise(ctx, current, userID); err != nil {
+				return "", nil, false, err
+			}
+			current.TotalSteps = len(ex.Steps)
+			return ex.Outro, current, true, nil
+		}
+		if err := h.db.SaveExerciseState(ctx, current); err != nil {
+			return "", nil, false, err
+		}
+		current.TotalSteps = len(ex.Steps)
+		return exerciseStepText(ex, current.Step, lang), current, true, nil
+	}
+	return "", nil, false, nil
+}
+
+func (h *Handler) startExercise(ctx context.Context, sessionID, code, lang string) (string, *models.ExerciseState, bool, error) {
+	ex, ok := exercise.Get(code, lang)
+	if !ok {
+		return "", nil, false, fmt.Errorf("unknown exercise %q", code)
+	}
+	st := &models.ExerciseState{SessionID: sessionID, Exercise: ex.Code, Step: 0, Status: exerciseActive}
+	if err := h.db.SaveExerciseState(ctx, st); err != nil {
+		return "", nil, false, err
+	}
+	st.TotalSteps = len(ex.Steps)
+	return ex.Intro + "\n\n" + exerciseStepText(ex, 0, lang), st, true, nil
+}
+
+// offerExercise suggests an exercise matching the message's category, once per session.
+func (h *Handler) offerExercise(ctx context.Context, sessionID, message, lang string) (string, *models.ExerciseState, error) {
+	ex, ok := exercise.ForCategory(chatbot.DetectCategory(message), lang)
+	if !ok {
+		return "", nil, nil
+	}
+	current, err := h.db.GetExerciseState(ctx, sessionID)
+	if err != nil || current != nil {
+		return "", nil, err
+	}
+	st := &models.ExerciseState{SessionID: sessionID, Exercise: ex.Code, Status: exerciseOffered}
+	if err := h.db.SaveExerciseState(ctx, st); err != nil {
+		return "", nil, err
+	}
+	st.TotalSteps = len(ex.Steps)
+	return "\n\n" + fmt.Sprintf(localeFor(lang).exerciseOffer, ex.Title), st, nil
+}
+
+func (h *Handler) finishExercise(ctx context.Context, st *models.ExerciseState, userID int) error {
+	if err := h.db.SaveExerciseState(ctx, st); err != nil {
+		return err
+	}
+	return h.db.LogExercise(ctx, st.SessionID, userID, st.Exercise, st.Status)
+}
+
+func exerciseStepText(ex exercise.Exercise, step int, lang string) string {
+	return fmt.Sprintf(localeFor(lang).exerciseStep, step+1, len(ex.Steps), ex.Steps[step])
+}
+
+// matchesWord reports whether a short reply contains one of words, as a whole
+// word or phrase. Replies longer than maxWords never match, so "co" or "thoi"
+// inside a real answer is ignored.
+func matchesWord(message string, words []string, maxWords int) bool {
+	msg := strings.ToLower(strings.TrimSpace(message))
+	fields := strings.FieldsFunc(msg, func(r rune) bool {
+		return r == ' ' || r == ',' || r == '.' || r == '!' || r == '?'
+	})
+	if len(fields) == 0 || len(fields) > maxWords {
+		return false
+	}
+	joined := " " + strings.Join(fields, " ") + " "
+	for _, w := range words {
+		if strings.Contains(joined, " "+w+" ") {
+			return true
+		}
+	}
+	return false
+}
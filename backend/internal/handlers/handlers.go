@@ -2,20 +2,41 @@ package handlers
 
 import (
 	"context"
+	"edu-web-backend/internal/chatbot"
+	"edu-web-backend/internal/exercise"
+	"edu-web-backend/internal/media"
+	"edu-web-backend/internal/medialink"
 	"edu-web-backend/internal/models"
+	"edu-web-backend/internal/notify"
+	"edu-web-backend/internal/redact"
 	"edu-web-backend/internal/repository"
+	"edu-web-backend/internal/storage"
+	"edu-web-backend/internal/summary"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	db *repository.DB
+	db          *repository.DB
+	redactor    *redact.Redactor
+	notifier    notify.Notifier
+	loc         *time.Location // school time zone for appointment slots
+	summarizer  summary.Summarizer
+	publicURL   string // base of QR short links
+	frontendURL string // base of pages that QR codes open in the web app
+
+	media         storage.Store // uploaded audio and video files
+	mediaMaxBytes int64
+
+	logos logoCache // decoded QR logos by id
 }
 
-func NewHandler(db *repository.DB) *Handler {
-	return &Handler{db: db}
+func NewHandler(db *repository.DB, redactor *redact.Redactor, notifier notify.Notifier, loc *time.Location, publicURL, frontendURL string, media storage.Store, mediaMaxBytes int64) *Handler {
+	return &Handler{db: db, redactor: redactor, notifier: notifier, loc: loc, summarizer: summary.NewRuleBased(), publicURL: publicURL, frontendURL: frontendURL, media: media, mediaMaxBytes: mediaMaxBytes}
 }
 
 func (h *Handler) GetVideos(c *gin.Context) {
@@ -42,44 +63,160 @@ func (h *Handler) GetAudios(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": audios, "total": len(audios)})
 }
 
-func (h *Handler) GetQRCodes(c *gin.Context) {
-	qrs, err := h.db.GetAllQRCodes(c.Request.Context())
+// contentRequest is the body of CreateVideo and CreateAudio. It names either
+// url, a Drive file or YouTube link, or media_id, a file in the media
+// library; the stored drive_url, embed_url and thumbnail are derived from it.
+// duration_seconds defaults to the length read from an uploaded file.
+type contentRequest struct {
+	Title           string   `json:"title" binding:"required"`
+	Description     string   `json:"description"`
+	URL             string   `json:"url"`
+	MediaID         *int     `json:"media_id"`
+	Category        string   `json:"category"`
+	Tags            []string `json:"tags"`
+	DurationSeconds *int     `json:"duration_seconds"`
+	Order           int      `json:"order"`
+}
+
+// maxContentSeconds bounds durations given by hand; a day is far beyond any
+// lesson or soundscape.
+const maxContentSeconds = 24 * 60 * 60
+
+// bindContent reads and validates a contentRequest for content of kind,
+// writing the error response and returning false when it is invalid.
+func (h *Handler) bindContent(c *gin.Context, kind string) (contentRequest, medialink.Link, bool) {
+	var req contentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, medialink.Link{}, false
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" || len(req.Title) > 255 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title must be 1-255 characters"})
+		return req, medialink.Link{}, false
+	}
+	if req.Category == "" {
+		req.Category = "general"
+	}
+	tags := []string{}
+	for _, t := range req.Tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !chatbot.IsCategory(t) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown tag " + t + "; tags are chatbot categories: " + strings.Join(chatbot.Categories(), ", ")})
+			return req, medialink.Link{}, false
+		}
+		tags = append(tags, t)
+	}
+	req.Tags = tags
+	if req.DurationSeconds != nil && (*req.DurationSeconds < 0 || *req.DurationSeconds > maxContentSeconds) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "duration_seconds must be between 0 and 86400"})
+		return req, medialink.Link{}, false
+	}
+
+	if (req.MediaID == nil) == (strings.TrimSpace(req.URL) == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "give either url or media_id"})
+		return req, medialink.Link{}, false
+	}
+	if req.MediaID != nil {
+		return h.bindMediaFile(c, req, kind)
+	}
+	link, err := medialink.Normalize(req.URL)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return req, link, false
+	}
+	return req, link, true
+}
+
+// bindMediaFile links the request to its library file, which must hold
+// content of kind; the file URL is both the link and the player source.
+func (h *Handler) bindMediaFile(c *gin.Context, req contentRequest, kind string) (contentRequest, medialink.Link, bool) {
+	m, err := h.db.GetMediaFile(c.Request.Context(), *req.MediaID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return req, medialink.Link{}, false
+	}
+	if m == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "media_id does not exist"})
+		return req, medialink.Link{}, false
 	}
-	if qrs == nil {
-		qrs = []models.QRCode{}
+	if m.Kind != kind {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "media file is " + m.Kind + ", not " + kind})
+		return req, medialink.Link{}, false
 	}
-	c.JSON(http.StatusOK, gin.H{"data": qrs, "total": len(qrs)})
+	if req.DurationSeconds == nil {
+		req.DurationSeconds = m.DurationSeconds
+	}
+	url := h.mediaURL(m.ID)
+	return req, medialink.Link{URL: url, EmbedURL: url}, true
 }
 
-func (h *Handler) GenerateQR(c *gin.Context) {
-	var req struct {
-		Label     string `json:"label" binding:"required"`
-		TargetURL string `json:"target_url" binding:"required"`
-		Type      string `json:"type"`
+// CreateVideo adds a video from a Drive file or YouTube link or an uploaded
+// file.
+func (h *Handler) CreateVideo(c *gin.Context) {
+	req, link, ok := h.bindContent(c, media.KindVideo)
+	if !ok {
+		return
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	v := &models.Video{
+		Title:           req.Title,
+		Description:     req.Description,
+		DriveURL:        link.URL,
+		EmbedURL:        link.EmbedURL,
+		Thumbnail:       link.Thumbnail,
+		Category:        req.Category,
+		Tags:            req.Tags,
+		Order:           req.Order,
+		MediaID:         req.MediaID,
+		DurationSeconds: req.DurationSeconds,
+	}
+	if err := h.db.CreateVideo(c.Request.Context(), v); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save video"})
 		return
 	}
-	if req.Type == "" {
-		req.Type = "general"
+	if v.DurationSeconds != nil {
+		v.Duration = media.FormatDuration(*v.DurationSeconds)
 	}
+	c.JSON(http.StatusCreated, gin.H{"data": v})
+}
 
-	qrData := generateQRBase64(req.TargetURL)
-	if qrData == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "QR generation failed"})
+// CreateAudio adds an audio track from a Drive file or YouTube link or an
+// uploaded file.
+func (h *Handler) CreateAudio(c *gin.Context) {
+	req, link, ok := h.bindContent(c, media.KindAudio)
+	if !ok {
+		return
+	}
+	a := &models.Audio{
+		Title:           req.Title,
+		Description:     req.Description,
+		DriveURL:        link.URL,
+		EmbedURL:        link.EmbedURL,
+		Category:        req.Category,
+		Tags:            req.Tags,
+		Order:           req.Order,
+		MediaID:         req.MediaID,
+		DurationSeconds: req.DurationSeconds,
+	}
+	if err := h.db.CreateAudio(c.Request.Context(), a); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save audio"})
 		return
 	}
+	if a.DurationSeconds != nil {
+		a.Duration = media.FormatDuration(*a.DurationSeconds)
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": a})
+}
 
-	qr, err := h.db.SaveQRCode(c.Request.Context(), req.Label, req.TargetURL, req.Type, qrData)
+// NormalizeMediaLink shows what a link would be stored as, so forms can
+// preview the player and flag folder links before saving.
+func (h *Handler) NormalizeMediaLink(c *gin.Context) {
+	link, err := medialink.Normalize(c.Query("url"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusCreated, gin.H{"data": qr})
+	c.JSON(http.StatusOK, gin.H{"data": link})
 }
 
 func (h *Handler) GetChatHistory(c *gin.Context) {
@@ -88,6 +225,9 @@ func (h *Handler) GetChatHistory(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "session_id required"})
 		return
 	}
+	if h.ownedSession(c, sessionID) == nil {
+		return
+	}
 	msgs, err := h.db.GetChatHistory(c.Request.Context(), sessionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -101,8 +241,12 @@ func (h *Handler) GetChatHistory(c *gin.Context) {
 
 func (h *Handler) SendChat(c *gin.Context) {
 	var req struct {
-		SessionID string `json:"session_id" binding:"required"`
+		SessionID string `json:"session_id"`
 		Message   string `json:"message" binding:"required"`
+		Exercise  string `json:"exercise"`
+		// Lang forces the reply language ("vi" or "en"); empty or "auto" detects it
+		// per message, keeping the session's language when the message does not tell.
+		Lang string `json:"lang"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -123,24 +267,120 @@ func (h *Handler) SendChat(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "message cannot be empty"})
 		return
 	}
+	if _, ok := exercise.Get(req.Exercise, chatbot.LangVietnamese); req.Exercise != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown exercise"})
+		return
+	}
+	if req.Lang != "" && req.Lang != "auto" && !chatbot.IsLanguage(req.Lang) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lang must be auto, vi or en"})
+		return
+	}
+
+	// Sessions are always created server-side; an empty session_id starts a new one.
+	var session *models.ChatSession
+	if req.SessionID == "" {
+		userID, anonID, err := ensureChatOwner(c)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create session"})
+			return
+		}
+		session, err = h.db.CreateChatSession(c.Request.Context(), userID, anonID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create session"})
+			return
+		}
+		req.SessionID = session.SessionID
+	} else if session = h.ownedSession(c, req.SessionID); session == nil {
+		return
+	}
 
-	if err := h.db.SaveChatMessage(c.Request.Context(), req.SessionID, "user", req.Message); err != nil {
+	// Only the redacted text is stored. The response engine reads the
+	// original, so a crisis statement next to a redacted address is still
+	// recognised; nothing it writes echoes the message back.
+	stored, redactions := h.redactor.Redact(req.Message)
+	if len(redactions) > 0 {
+		if err := h.db.SaveRedactionAudit(c.Request.Context(), req.SessionID, redactions); err != nil {
+			log.Printf("redaction audit: %v", err)
+		}
+	}
+
+	lang := req.Lang
+	if lang == "" || lang == "auto" {
+		fallback := session.Lang
+		if !chatbot.IsLanguage(fallback) {
+			fallback = chatbot.LangVietnamese
+		}
+		lang = chatbot.DetectLanguageOr(req.Message, fallback)
+	}
+	if lang != session.Lang {
+		if err := h.db.SetChatSessionLang(c.Request.Context(), req.SessionID, lang); err != nil {
+			log.Printf("chat session lang: %v", err)
+		}
+	}
+
+	priorMessages, err := h.db.CountChatMessages(c.Request.Context(), req.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	crisis := chatbot.IsCrisis(req.Message)
+	if err := h.db.SaveChatMessage(c.Request.Context(), req.SessionID, "user", stored, crisis); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, _ := chatOwner(c)
+	suggestions := []models.ContentSuggestion{}
+	var plan string
+	response, exerciseState, handled, err := h.exerciseTurn(c.Request.Context(), req.SessionID, userID, req.Message, req.Exercise, lang)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if !handled {
+		reply := buildAIResponse(c.Request.Context(), req.Message, userID, lang, h.db)
+		response, plan = reply.Text, reply.SafetyPlan
+
+		// On the first turn of a session, check in with students who logged several low-mood days.
+		if userID > 0 && priorMessages == 0 && h.hasLowMoodStreak(c.Request.Context(), userID) {
+			response += "\n\n" + localeFor(lang).lowMoodFollowUp
+		}
+
+		if !crisis {
+			suggestions = h.suggestContent(c.Request.Context(), req.Message, userID)
+			response += suggestionText(suggestions, lang)
+
+			offer, offered, err := h.offerExercise(c.Request.Context(), req.SessionID, req.Message, lang)
+			if err != nil {
+				log.Printf("exercise offer: %v", err)
+			}
+			response += offer
+			exerciseState = offered
+		}
+	}
 
-	response := buildAIResponse(c.Request.Context(), req.Message, h.db)
+	// The safety plan lists the student's contacts, so history keeps a
+	// placeholder; the plan itself is on the safety plan endpoint.
+	storedResponse := response
+	if plan != "" {
+		storedResponse += "\n\n" + localeFor(lang).safetyPlanShown
+		response += "\n\n" + plan
+	}
 
 	// Use context.WithoutCancel so a client disconnect does not orphan the assistant message.
 	saveCtx := context.WithoutCancel(c.Request.Context())
-	if err := h.db.SaveChatMessage(saveCtx, req.SessionID, "assistant", response); err != nil {
+	if err := h.db.SaveChatMessage(saveCtx, req.SessionID, "assistant", storedResponse, false); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"response":   response,
-		"session_id": req.SessionID,
+		"response":    response,
+		"session_id":  req.SessionID,
+		"exercise":    exerciseState,
+		"suggestions": suggestions,
+		"lang":        lang,
 	})
 }
 
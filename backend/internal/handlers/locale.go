@@ -0,0 +1,126 @@
+package handlers
+
+import "edu-web-backend/internal/chatbot"
+
+type greeting struct{ kw, resp string }
+
+// chatLocale holds Buddy's built-in texts in one language. Scenario replies
+// come from the psych_scenarios rows with the same lang.
+type chatLocale struct {
+	greetings    []greeting
+	defaultReply string
+	// triggerKeywords pick a specific scenario before falling back to a random
+	// one from the detected category.
+	triggerKeywords []string
+	tipPrefix       string
+
+	// crisisKeyword finds the crisis scenario; crisisFallback is used when it
+	// is missing. Both carry the hotline for this locale.
+	crisisKeyword    string
+	crisisFallback   string
+	safetyPlanPrompt string
+	safetyPlan       safetyPlanLabels
+	// safetyPlanShown stands in for the plan in the stored chat history.
+	safetyPlanShown string
+
+	lowMoodFollowUp  string
+	suggestionHeader string
+
+	// exerciseOffer and exerciseStopped take the exercise title; exerciseStep
+	// takes the step number, the step count and the step text.
+	exerciseOffer   string
+	exerciseStopped string
+	exerciseStep    string
+}
+
+type safetyPlanLabels struct {
+	title, warningSigns, coping, contacts, professionals, environment string
+}
+
+var chatLocales = map[string]chatLocale{
+	chatbot.LangVietnamese: {
+		greetings: []greeting{
+			{"xin chao", "Xin chao! Minh la Buddy AI - nguoi ban dong hanh tam ly 24/7. Ban dang cam thay the nao?"},
+			{"chao", "Xin chao! Minh la Buddy AI - nguoi ban dong hanh 24/7. Ban dang cam thay the nao hom nay?"},
+			{"hello", "Hello! Minh o day de lang nghe ban. Hay chia se bat cu dieu gi ban muon nhe!"},
+			{"hi ", "Hi! Buddy AI day. Ban can minh ho tro gi hom nay?"},
+			{"hoc", "Hoc tap doi khi rat thu thach. Ban dang gap kho khan o diem nao?"},
+			{"met", "Met moi la tin hieu co the can nghi ngoi. Ban dang met vi dieu gi?"},
+			{"khoc", "Duoc khoc la dieu binh thuong. Minh o day ben ban. Chuyen gi dang xay ra vay?"},
+			{"ap luc", "Ap luc co the rat nang ne. Hay chia se them de minh hieu ban dang doi mat voi gi nhe."},
+			{"co don", "Cam giac co don rat pho bien. Ban khong he mot minh - minh luon o day lang nghe."},
+		},
+		defaultReply: "Cam on ban da chia se! Minh dang lang nghe. Ban co the ke them de minh hieu ro hon va ho tro ban tot hon khong?\n\nNgoai ra, ban co the thu:\n- Nghe am thanh song nao trong muc Audio\n- Xem video meo hoc tap\n- Quet ma QR de truy cap nhanh tai nguyen",
+		triggerKeywords: []string{
+			"thi cu", "kiem tra", "bai tap",
+			"bo me", "gia dinh", "ban be",
+			"thay co", "dien thoai", "game",
+			"ngu", "tap trung", "mat dong luc",
+			"tu ti", "buon", "lo lang",
+			"stress", "truot", "chan", "luoi",
+		},
+		tipPrefix:        "\n\n Meo: ",
+		crisisKeyword:    "tu tu",
+		crisisFallback:   "Minh rat lo lang khi nghe dieu nay. Ban khong co don - co nguoi san sang lang nghe va giup ban ngay bay gio.\n\nDuong day ho tro khung hoang tam than Viet Nam: 1800 599 920 (mien phi, 24/7)\n\nHay goi ngay nhe. Minh o day ben ban.",
+		safetyPlanPrompt: "Khi ban thay on hon, hay cung minh (hoac thay co tu van) lap mot ke hoach an toan trong muc Ke hoach an toan nhe.",
+		safetyPlan: safetyPlanLabels{
+			title:         "Ke hoach an toan cua ban:",
+			warningSigns:  "Dau hieu canh bao:",
+			coping:        "Viec ban co the tu lam de diu lai:",
+			contacts:      "Nguoi ban co the lien lac ngay:",
+			professionals: "Chuyen gia / thay co tu van:",
+			environment:   "Giu moi truong an toan:",
+		},
+		safetyPlanShown:  "[Da hien thi ke hoach an toan cua ban]",
+		lowMoodFollowUp:  "Minh thay may ngay gan day tam trang cua ban khong duoc tot lam. Ban co muon ke cho minh nghe chuyen gi dang xay ra khong? Neu ban thay qua suc, noi chuyen voi thay co tu van cung la mot lua chon rat tot.",
+		suggestionHeader: "Minh goi y cho ban:",
+		exerciseOffer:    "Minh co mot bai tap nho co the giup ban: \"%s\". Ban co muon thu ngay khong? (tra loi 'co' de bat dau)",
+		exerciseStopped:  "Minh da dung bai tap \"%s\". Khong sao ca, ban co the quay lai bat cu luc nao. Ban muon chia se them dieu gi khong?",
+		exerciseStep:     "Buoc %d/%d: %s",
+	},
+	chatbot.LangEnglish: {
+		greetings: []greeting{
+			{"hello", "Hello! I'm Buddy, your 24/7 wellbeing companion. How are you feeling today?"},
+			{"hi ", "Hi! Buddy here. What can I help you with today?"},
+			{"hey", "Hey! I'm here to listen. How are you doing?"},
+			{"good morning", "Good morning! How are you feeling today?"},
+			{"study", "Studying can be really challenging. Which part is giving you trouble?"},
+			{"tired", "Feeling tired can be a sign you need rest. What has been wearing you out?"},
+			{"cry", "It's okay to cry. I'm here with you. What's been happening?"},
+		},
+		defaultReply: "Thank you for sharing! I'm listening. Could you tell me a bit more so I can understand and support you better?\n\nYou can also:\n- Listen to the brainwave tracks in the Audio section\n- Watch the study tips videos\n- Scan a QR code for quick access to resources",
+		triggerKeywords: []string{
+			"exam", "homework", "deadline", "parents", "family",
+			"panic", "nervous", "worried", "procrastinate", "give up",
+			"phone", "social media", "focus", "sleep", "nightmare",
+			"homesick", "lonely", "breakup", "worthless", "ugly",
+			"grades", "sad", "hopeless",
+		},
+		tipPrefix:        "\n\nTip: ",
+		crisisKeyword:    "suicide",
+		crisisFallback:   "I'm really worried to hear this. You are not alone - there are people ready to listen and help you right now.\n\nEmergency: 115. Vietnam mental health hotline: 1800 599 920 (free, 24/7, Vietnamese). For a line in your home country, see findahelpline.com\n\nPlease reach out now, or tell a trusted adult at school. I'm here with you.",
+		safetyPlanPrompt: "When you feel a little better, let's make a safety plan together (or with a school counselor) in the Safety plan section.",
+		safetyPlan: safetyPlanLabels{
+			title:         "Your safety plan:",
+			warningSigns:  "Warning signs:",
+			coping:        "Things you can do to calm down:",
+			contacts:      "People you can contact right now:",
+			professionals: "Professionals / school counselors:",
+			environment:   "Keeping your surroundings safe:",
+		},
+		safetyPlanShown:  "[Your safety plan was shown]",
+		lowMoodFollowUp:  "I noticed your mood hasn't been great the last few days. Would you like to tell me what's been going on? If it feels like too much, talking with a school counselor is a really good option too.",
+		suggestionHeader: "Here are some things you could try:",
+		exerciseOffer:    "I have a short exercise that might help: \"%s\". Would you like to try it now? (reply 'yes' to start)",
+		exerciseStopped:  "I've stopped the \"%s\" exercise. That's completely fine, you can come back to it any time. Is there anything else you'd like to share?",
+		exerciseStep:     "Step %d/%d: %s",
+	},
+}
+
+// localeFor returns the texts for lang, falling back to Vietnamese.
+func localeFor(lang string) chatLocale {
+	if l, ok := chatLocales[lang]; ok {
+		return l
+	}
+	return chatLocales[chatbot.LangVietnamese]
+}
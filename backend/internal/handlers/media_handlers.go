@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"edu-web-backend/internal/media"
+	"edu-web-backend/internal/models"
+	"edu-web-backend/internal/repository"
+	"edu-web-backend/internal/storage"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadMedia adds an audio or video file to the media library. The file is
+// streamed to storage as it arrives, so large lectures are never held in
+// memory.
+func (h *Handler) UploadMedia(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.mediaMaxBytes+1<<20)
+	mr, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "upload must be multipart/form-data with a file field"})
+		return
+	}
+	var part io.ReadCloser
+	var filename string
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+			return
+		}
+		if p.FormName() == "file" {
+			part, filename = p, p.FileName()
+			break
+		}
+		p.Close()
+	}
+	defer part.Close()
+
+	br := bufio.NewReaderSize(part, media.SniffLen)
+	head, _ := br.Peek(media.SniffLen)
+	format, err := media.Sniff(head)
+	if err != nil {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+
+	m := &models.MediaFile{
+		Filename:    mediaFilename(filename, format.Ext),
+		ContentType: format.ContentType,
+		Kind:        format.Kind,
+	}
+	if m.StorageKey, err = newStorageKey(format.Ext); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store file"})
+		return
+	}
+	if uid, ok := c.Get("user_id"); ok {
+		id := uid.(int)
+		m.UploadedBy = &id
+	}
+
+	ctx := c.Request.Context()
+	sum := sha256.New()
+	m.SizeBytes, err = h.media.Put(ctx, m.StorageKey, io.TeeReader(br, sum))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file must be at most %d MB", h.mediaMaxBytes>>20)})
+		return
+	}
+	if err != nil {
+		log.Printf("media upload: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store file"})
+		return
+	}
+	if m.SizeBytes > h.mediaMaxBytes {
+		h.deleteStored(c, m.StorageKey)
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("file must be at most %d MB", h.mediaMaxBytes>>20)})
+		return
+	}
+	m.SHA256 = hex.EncodeToString(sum.Sum(nil))
+	h.probeMedia(c, m, format)
+
+	if err := h.db.CreateMediaFile(ctx, m); err != nil {
+		h.deleteStored(c, m.StorageKey)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save media file"})
+		return
+	}
+	m.URL = h.mediaURL(m.ID)
+	c.JSON(http.StatusCreated, gin.H{"data": m})
+}
+
+func (h *Handler) GetMediaFiles(c *gin.Context) {
+	files, err := h.db.ListMediaFiles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	if files == nil {
+		files = []models.MediaFile{}
+	}
+	for i := range files {
+		files[i].URL = h.mediaURL(files[i].ID)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": files, "total": len(files)})
+}
+
+// maxProbedBitrate is well above any lecture or screen recording, in bits
+// per second.
+const maxProbedBitrate = 100_000_000
+
+// probeMedia fills in the length, bitrate, codecs and dimensions read from
+// the stored file. They are only informational, so a file whose headers
+// cannot be read is still accepted.
+func (h *Handler) probeMedia(c *gin.Context, m *models.MediaFile, format media.Format) {
+	obj, err := h.media.Open(c.Request.Context(), m.StorageKey)
+	if err != nil {
+		log.Printf("media probe %s: %v", m.StorageKey, err)
+		return
+	}
+	defer obj.Close()
+	info, err := media.Probe(obj, m.SizeBytes, format)
+	if err != nil {
+		if !errors.Is(err, media.ErrNoMetadata) {
+			log.Printf("media probe %s: %v", m.StorageKey, err)
+		}
+		return
+	}
+	// Damaged or crafted headers can claim any length; values no real
+	// upload has are dropped rather than shown.
+	if secs := int(math.Round(info.Duration.Seconds())); secs > 0 && secs <= maxContentSeconds {
+		m.DurationSeconds = &secs
+		m.Duration = media.FormatDuration(secs)
+	}
+	if info.Bitrate > 0 && info.Bitrate <= maxProbedBitrate {
+		m.Bitrate = &info.Bitrate
+	}
+	if info.Width > 0 && info.Height > 0 {
+		m.Width, m.Height = &info.Width, &info.Height
+	}
+	m.Codec = info.Codec
+}
+
+// ServeMedia streams a library file. http.ServeContent answers Range
+// requests with 206, so players can seek and resume, and conditional
+// requests with 304 against the content hash ETag.
+func (h *Handler) ServeMedia(c *gin.Context) {
+	m := h.loadMediaFile(c)
+	if m == nil {
+		return
+	}
+	obj, err := h.media.Open(c.Request.Context(), m.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "media file not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("media %d: %v", m.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read file"})
+		return
+	}
+	defer obj.Close()
+
+	c.Header("Content-Type", m.ContentType)
+	c.Header("ETag", `"`+m.SHA256+`"`)
+	c.Header("X-Content-Type-Options", "nosniff")
+	// A file never changes under its id; a replacement is a new upload.
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": m.Filename}))
+	http.ServeContent(c.Writer, c.Request, m.Filename, m.CreatedAt, obj)
+}
+
+// DeleteMedia removes a file no video or audio plays any more.
+func (h *Handler) DeleteMedia(c *gin.Context) {
+	m := h.loadMediaFile(c)
+	if m == nil {
+		return
+	}
+	err := h.db.DeleteMediaFile(c.Request.Context(), m.ID)
+	if errors.Is(err, repository.ErrMediaInUse) {
+		c.JSON(http.StatusConflict, gin.H{"error": "file is used by a video or audio; remove those first"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	h.deleteStored(c, m.StorageKey)
+	c.Status(http.StatusNoContent)
+}
+
+// loadMediaFile loads the file named by the :id parameter, writing the error
+// response and returning nil when it cannot.
+func (h *Handler) loadMediaFile(c *gin.Context) *models.MediaFile {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return nil
+	}
+	m, err := h.db.GetMediaFile(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return nil
+	}
+	if m == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "media file not found"})
+		return nil
+	}
+	m.URL = h.mediaURL(m.ID)
+	return m
+}
+
+// deleteStored removes a stored file whose row is gone or was never written.
+// Failures only leave an orphan on disk, so they are logged.
+func (h *Handler) deleteStored(c *gin.Context, key string) {
+	if err := h.media.Delete(c.Request.Context(), key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		log.Printf("media cleanup %s: %v", key, err)
+	}
+}
+
+func (h *Handler) mediaURL(id int) string {
+	return h.publicURL + "/api/v1/media/" + strconv.Itoa(id) + "/file"
+}
+
+func newStorageKey(ext string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf) + ext, nil
+}
+
+// mediaFilename keeps the uploaded name for display and downloads, without
+// any client path, falling back to a generic name.
+func mediaFilename(name, ext string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" || !utf8.ValidString(name) {
+		return "media" + ext
+	}
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"edu-web-backend/internal/chatbot"
+	"edu-web-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lowMoodScore is the highest daily average that still counts as a low day.
+const lowMoodScore = 2.0
+
+// lowMoodStreakDays is how many consecutive low days trigger a Buddy follow-up.
+const lowMoodStreakDays = 3
+
+func (h *Handler) CreateMoodEntry(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req struct {
+		Score int      `json:"score"`
+		Tags  []string `json:"tags"`
+		Note  string   `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Score < 1 || req.Score > 5 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "score must be between 1 and 5"})
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if len(req.Note) > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "note too long (max 1000)"})
+		return
+	}
+
+	// Tags reuse the chatbot's category set so moods and chats can be compared.
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, t := range req.Tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if !chatbot.IsCategory(t) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown tag: " + t, "allowed_tags": chatbot.Categories()})
+			return
+		}
+		if !seen[t] {
+			seen[t] = true
+			tags = append(tags, t)
+		}
+	}
+
+	note, _ := h.redactor.Redact(req.Note)
+	entry, err := h.db.CreateMoodEntry(c.Request.Context(), userID.(int), req.Score, tags, note)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save mood entry"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": entry})
+}
+
+func (h *Handler) GetMoodEntries(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -30)
+	entries, err := h.db.GetMoodEntriesSince(c.Request.Context(), userID.(int), since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch mood entries"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": entries, "total": len(entries)})
+}
+
+// GetMoodTrends aggregates entries into daily points for ?period=week and
+// weekly points for ?period=month. Days and weeks start at midnight school
+// time, the same days the low mood streak counts.
+func (h *Handler) GetMoodTrends(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	period := c.DefaultQuery("period", "week")
+	now := time.Now().In(h.loc)
+	var since time.Time
+	var bucket func(time.Time) time.Time
+	switch period {
+	case "week":
+		since = startOfDay(now).AddDate(0, 0, -6)
+		bucket = startOfDay
+	case "month":
+		since = startOfWeek(now).AddDate(0, 0, -7*4)
+		bucket = startOfWeek
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "period must be week or month"})
+		return
+	}
+
+	entries, err := h.db.GetMoodEntriesSince(c.Request.Context(), userID.(int), since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch mood entries"})
+		return
+	}
+
+	points := map[time.Time]*models.MoodTrendPoint{}
+	sums := map[time.Time]int{}
+	total, sum := 0, 0
+	for _, e := range entries {
+		key := bucket(e.CreatedAt.In(h.loc))
+		p, ok := points[key]
+		if !ok {
+			p = &models.MoodTrendPoint{PeriodStart: key, TagCounts: map[string]int{}}
+			points[key] = p
+		}
+		p.Count++
+		sums[key] += e.Score
+		for _, t := range e.Tags {
+			p.TagCounts[t]++
+		}
+		total++
+		sum += e.Score
+	}
+
+	trend := make([]models.MoodTrendPoint, 0, len(points))
+	for key, p := range points {
+		p.Average = float64(sums[key]) / float64(p.Count)
+		trend = append(trend, *p)
+	}
+	sort.Slice(trend, func(i, j int) bool { return trend[i].PeriodStart.Before(trend[j].PeriodStart) })
+
+	average := 0.0
+	if total > 0 {
+		average = float64(sum) / float64(total)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"data":    trend,
+		"period":  period,
+		"since":   since,
+		"average": average,
+		"total":   total,
+	})
+}
+
+// hasLowMoodStreak reports whether the user's last lowMoodStreakDays logged days
+// in school time are consecutive, recent and all low.
+func (h *Handler) hasLowMoodStreak(ctx context.Context, userID int) bool {
+	days, err := h.db.GetRecentDailyMoods(ctx, userID, lowMoodStreakDays, h.loc)
+	if err != nil || len(days) < lowMoodStreakDays {
+		return false
+	}
+	// The streak must reach today or yesterday to still be relevant.
+	if dateOnly(time.Now().In(h.loc)).Sub(days[0].Day) > 24*time.Hour {
+		return false
+	}
+	for i, d := range days {
+		if d.Average > lowMoodScore {
+			return false
+		}
+		if i > 0 && days[i-1].Day.Sub(d.Day) != 24*time.Hour {
+			return false
+		}
+	}
+	return true
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// dateOnly returns t's calendar date as midnight UTC, matching how Postgres
+// DATE values are scanned.
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// startOfWeek returns the Monday of t's week.
+func startOfWeek(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -offset)
+}
@@ -0,0 +1,990 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"edu-web-backend/internal/models"
+	"edu-web-backend/internal/qr"
+	"edu-web-backend/internal/qrbatch"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetQRCodes lists public codes that have not expired plus the caller's own;
+// admins see every code.
+func (h *Handler) GetQRCodes(c *gin.Context) {
+	viewerID, admin, err := h.qrViewer(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	qrs, err := h.db.GetAllQRCodes(c.Request.Context(), viewerID, admin)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if qrs == nil {
+		qrs = []models.QRCode{}
+	}
+	for i := range qrs {
+		h.fillQRCode(&qrs[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"data": qrs, "total": len(qrs)})
+}
+
+// GetQRCodeTypes lists the structured payload types and their fields.
+func (h *Handler) GetQRCodeTypes(c *gin.Context) {
+	schemas := qr.Schemas()
+	c.JSON(http.StatusOK, gin.H{"data": schemas, "total": len(schemas)})
+}
+
+// GenerateQR stores a code. Link codes encode their /q/:slug short link, so
+// the destination can change after the code is printed; payload types (wifi,
+// vcard, mecard, event, geo, sms) encode their fields directly. The caller
+// owns the new code.
+func (h *Handler) GenerateQR(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	var req struct {
+		Label      string          `json:"label" binding:"required"`
+		TargetURL  string          `json:"target_url"`
+		Type       string          `json:"type"`
+		Payload    json.RawMessage `json:"payload"`
+		Visibility string          `json:"visibility"`
+		ExpiresAt  *time.Time      `json:"expires_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Label = strings.TrimSpace(req.Label)
+	if req.Type == "" {
+		req.Type = "general"
+	}
+	if req.Visibility == "" {
+		req.Visibility = models.QRPublic
+	}
+	if err := validateQRDetails(req.Label, req.Visibility, req.ExpiresAt); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var payload json.RawMessage
+	if qr.IsPayloadType(req.Type) {
+		if req.TargetURL != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "type " + req.Type + " takes a payload, not a target_url"})
+			return
+		}
+		var err error
+		if payload, err = normalizePayload(req.Type, req.Payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else {
+		if len(req.Payload) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "payload is only used by types wifi, vcard, mecard, event, geo and sms"})
+			return
+		}
+		if err := qr.ValidateTargetURL(req.TargetURL); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	owner := userID.(int)
+	code, err := h.db.SaveQRCode(c.Request.Context(), &models.QRCode{
+		Label:      req.Label,
+		TargetURL:  req.TargetURL,
+		Type:       req.Type,
+		Payload:    payload,
+		CreatedBy:  &owner,
+		Visibility: req.Visibility,
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	h.fillQRCode(code)
+	c.JSON(http.StatusCreated, gin.H{"data": code})
+}
+
+// UpdateQRCode changes the label, visibility or expiry of a code. Fields
+// left out keep their value; "expires_at": null makes the code permanent.
+func (h *Handler) UpdateQRCode(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req struct {
+		Label      *string `json:"label"`
+		Visibility *string `json:"visibility"`
+		// Kept raw so that null (clear the expiry) differs from leaving it out.
+		ExpiresAt json.RawMessage `json:"expires_at"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code := h.loadOwnQRCode(c, id)
+	if code == nil {
+		return
+	}
+	label, visibility, expiresAt := code.Label, code.Visibility, code.ExpiresAt
+	if req.Label != nil {
+		label = strings.TrimSpace(*req.Label)
+	}
+	if req.Visibility != nil {
+		visibility = *req.Visibility
+	}
+	// Only a new expiry must lie in the future; an expired code can still
+	// be relabelled or hidden without reviving it.
+	var newExpiry *time.Time
+	if len(req.ExpiresAt) > 0 {
+		expiresAt = nil
+		if err := json.Unmarshal(req.ExpiresAt, &expiresAt); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be an RFC 3339 time or null"})
+			return
+		}
+		newExpiry = expiresAt
+	}
+	if err := validateQRDetails(label, visibility, newExpiry); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code, err = h.db.UpdateQRCodeDetails(c.Request.Context(), id, label, visibility, expiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update QR code"})
+		return
+	}
+	if code == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "QR code not found"})
+		return
+	}
+	h.fillQRCode(code)
+	c.JSON(http.StatusOK, gin.H{"data": code})
+}
+
+// DeleteQRCode removes a code and its scans. Printed copies stop working.
+func (h *Handler) DeleteQRCode(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	if h.loadOwnQRCode(c, id) == nil {
+		return
+	}
+	found, err := h.db.DeleteQRCode(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete QR code"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "QR code not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "QR code deleted"})
+}
+
+// validateQRDetails checks the fields shared by GenerateQR and UpdateQRCode.
+// expiresAt is nil when the request leaves the expiry as it is.
+func validateQRDetails(label, visibility string, expiresAt *time.Time) error {
+	if label == "" || len(label) > 255 {
+		return fmt.Errorf("label must be 1-255 characters")
+	}
+	if visibility != models.QRPublic && visibility != models.QRPrivate {
+		return fmt.Errorf("visibility must be %s or %s", models.QRPublic, models.QRPrivate)
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+	return nil
+}
+
+// maxBulkCSVBytes bounds the CSV accepted by BulkCreateQR.
+const maxBulkCSVBytes = 1 << 20
+
+// BulkCreateQR creates a link code for every label,target_url,type row of
+// an uploaded CSV (multipart field "file" or a text/csv body) in one
+// transaction. It returns a printable A4 sheet (?output=pdf, default) or a
+// ZIP of images (?output=zip) rendered with the usual image options.
+func (h *Handler) BulkCreateQR(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	output := strings.ToLower(c.DefaultQuery("output", "pdf"))
+	if output != "pdf" && output != "zip" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "output must be pdf or zip"})
+		return
+	}
+	opts, err := qrOptionsFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBulkCSVBytes+64<<10)
+	var body io.Reader = c.Request.Body
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fh, err := c.FormFile("file")
+		if err != nil || fh.Size > maxBulkCSVBytes {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("file must be a CSV of at most %d KB", maxBulkCSVBytes>>10)})
+			return
+		}
+		f, err := fh.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read upload"})
+			return
+		}
+		defer f.Close()
+		body = f
+	}
+
+	codes, rowErrs, err := qrbatch.ParseCSV(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "errors": rowErrs})
+		return
+	}
+	owner := userID.(int)
+	for i := range codes {
+		codes[i].CreatedBy = &owner
+	}
+	codes, err = qrbatch.Create(c.Request.Context(), h.db, codes, h.publicURL)
+	if err != nil {
+		log.Printf("bulk qr: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create QR codes"})
+		return
+	}
+
+	name := fmt.Sprintf("qrcodes-%s", time.Now().In(h.loc).Format("20060102-150405"))
+	if output == "zip" {
+		var buf bytes.Buffer
+		if err := qrbatch.ZIP(&buf, codes, opts); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.zip"`, name))
+		c.Data(http.StatusCreated, "application/zip", buf.Bytes())
+		return
+	}
+	sheet, err := qrbatch.Sheet(codes, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, name))
+	c.Data(http.StatusCreated, "application/pdf", sheet)
+}
+
+// UpdateQRCodeTarget points an existing (possibly printed) code somewhere else.
+func (h *Handler) UpdateQRCodeTarget(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req struct {
+		TargetURL string `json:"target_url" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := qr.ValidateTargetURL(req.TargetURL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	existing := h.loadOwnQRCode(c, id)
+	if existing == nil {
+		return
+	}
+	if existing.Payload != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "QR code has a payload, not a link; update its payload instead"})
+		return
+	}
+
+	code, err := h.db.UpdateQRCodeTarget(c.Request.Context(), id, req.TargetURL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update QR code"})
+		return
+	}
+	if code == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "QR code not found"})
+		return
+	}
+	h.fillQRCode(code)
+	c.JSON(http.StatusOK, gin.H{"data": code})
+}
+
+// UpdateQRCodePayload replaces the fields of a payload code; the image
+// changes with them.
+func (h *Handler) UpdateQRCodePayload(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	var req struct {
+		Payload json.RawMessage `json:"payload" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code := h.loadOwnQRCode(c, id)
+	if code == nil {
+		return
+	}
+	if code.Payload == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "QR code is a link; change its target instead"})
+		return
+	}
+	payload, err := normalizePayload(code.Type, req.Payload)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code, err = h.db.UpdateQRCodePayload(c.Request.Context(), id, code.Type, payload)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update QR code"})
+		return
+	}
+	if code == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "QR code not found"})
+		return
+	}
+	h.fillQRCode(code)
+	c.JSON(http.StatusOK, gin.H{"data": code})
+}
+
+// RedirectQR is what a scanned code opens. It records the scan and sends the
+// browser on to the current destination; the redirect is temporary and not
+// cached so edits apply to codes already printed.
+func (h *Handler) RedirectQR(c *gin.Context) {
+	code, err := h.db.GetQRCodeBySlug(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	if code == nil || code.TargetURL == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "QR code not found"})
+		return
+	}
+	if code.ExpiresAt != nil && !time.Now().Before(*code.ExpiresAt) {
+		c.Header("Cache-Control", "no-store")
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.Status(http.StatusGone)
+		page := struct{ Label, ExpiredOn string }{code.Label, code.ExpiresAt.In(h.loc).Format("02/01/2006")}
+		if err := expiredQRPage.Execute(c.Writer, page); err != nil {
+			log.Printf("render expired page for QR %d: %v", code.ID, err)
+		}
+		return
+	}
+
+	ua := c.Request.UserAgent()
+	scan := models.QRScan{
+		QRCodeID:  code.ID,
+		UserAgent: truncate(ua, 300),
+		Referer:   truncate(c.Request.Referer(), 500),
+		Device:    deviceType(ua),
+	}
+	// A failed insert only loses one analytics row; the student still gets through.
+	if err := h.db.RecordQRScan(c.Request.Context(), scan); err != nil {
+		log.Printf("record scan of QR %d: %v", code.ID, err)
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, code.TargetURL)
+}
+
+// DecodeQR reads the QR code in an uploaded photo or screenshot (multipart
+// field "file"), for students who receive a code on the phone they would
+// scan it with. When the text belongs to a stored code (one of its short
+// links, a link target or a payload) that the caller may see, the code is
+// returned too.
+func (h *Handler) DecodeQR(c *gin.Context) {
+	data, ok := readImageUpload(c, "file", qr.MaxDecodeBytes)
+	if !ok {
+		return
+	}
+	text, err := qr.DecodeImage(data)
+	if errors.Is(err, qr.ErrNoCode) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code, err := h.matchQRCode(c, text)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	resp := gin.H{"text": text}
+	if code != nil {
+		resp["qrcode"] = code
+	}
+	c.JSON(http.StatusOK, gin.H{"data": resp})
+}
+
+// matchQRCode finds the visible stored code whose content is text, or nil.
+func (h *Handler) matchQRCode(c *gin.Context, text string) (*models.QRCode, error) {
+	var candidates []models.QRCode
+	if slug, ok := h.shortLinkSlug(text); ok {
+		code, err := h.db.GetQRCodeBySlug(c.Request.Context(), slug)
+		if err != nil || code == nil {
+			return nil, err
+		}
+		candidates = []models.QRCode{*code}
+	} else {
+		var err error
+		if candidates, err = h.db.FindQRCodesByContent(c.Request.Context(), text); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range candidates {
+		code := &candidates[i]
+		if code.Payload != nil {
+			if content, err := h.qrContent(code); err != nil || content != text {
+				continue
+			}
+		}
+		if code.Visibility == models.QRPrivate {
+			owner, err := h.ownsQRCode(c, code)
+			if err != nil {
+				return nil, err
+			}
+			if !owner {
+				continue
+			}
+		}
+		h.fillQRCode(code)
+		return code, nil
+	}
+	return nil, nil
+}
+
+// shortLinkSlug returns the slug when text is a /q/:slug link of this
+// server, whatever its scheme.
+func (h *Handler) shortLinkSlug(text string) (string, bool) {
+	u, err := url.Parse(text)
+	if err != nil {
+		return "", false
+	}
+	base, err := url.Parse(h.publicURL)
+	if err != nil || !strings.EqualFold(u.Host, base.Host) {
+		return "", false
+	}
+	slug, ok := strings.CutPrefix(u.Path, strings.TrimSuffix(base.Path, "/")+"/q/")
+	return slug, ok && slug != "" && !strings.Contains(slug, "/")
+}
+
+// expiredQRPage is what people see when they scan a code past its expiry.
+// A page rather than JSON, since it opens in a phone browser.
+var expiredQRPage = template.Must(template.New("expired").Parse(`<!DOCTYPE html>
+<html lang="vi">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Ma QR da het han</title>
+<style>
+body{margin:0;min-height:100vh;display:flex;align-items:center;justify-content:center;font-family:system-ui,sans-serif;background:#fff7ed;color:#374151}
+main{max-width:22rem;margin:1.5rem;padding:2rem;background:#fff;border-radius:1rem;box-shadow:0 4px 16px rgba(0,0,0,.08);text-align:center}
+h1{font-size:1.25rem;color:#111827;margin:0 0 .75rem}
+p{margin:.5rem 0;line-height:1.5}
+.label{font-weight:600}
+</style>
+</head>
+<body>
+<main>
+<h1>Ma QR nay da het han</h1>
+<p class="label">{{.Label}}</p>
+<p>Lien ket nay khong con hoat dong tu {{.ExpiredOn}}. Vui long lien he nguoi da tao ma QR de nhan lien ket moi.</p>
+</main>
+</body>
+</html>
+`))
+
+// GetQRScanStats reports scans of one code over the last days (default 30)
+// to its owner or an admin.
+func (h *Handler) GetQRScanStats(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days <= 0 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+		return
+	}
+
+	if h.loadOwnQRCode(c, id) == nil {
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	stats, err := h.db.GetQRScanStats(c.Request.Context(), id, since, h.loc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch scan stats"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": stats, "since": since})
+}
+
+// GetQRCodeImage renders a stored code's short link or payload. Query
+// parameters format (svg, png, pdf), size, ecc (L, M, Q, H) and margin
+// override the defaults; fg, bg, rounded, caption and logo (a stored logo id)
+// brand it. The ETag covers the content and options, so clients and proxies
+// can cache the image until the code changes.
+func (h *Handler) GetQRCodeImage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	opts, err := qrOptionsFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logoKey := c.Query("logo")
+	if logoKey != "" {
+		logoID, err := strconv.Atoi(logoKey)
+		if err != nil || logoID <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid logo"})
+			return
+		}
+		if opts.Logo = h.storedLogo(c, logoID); opts.Logo == nil {
+			return
+		}
+	}
+
+	code, content := h.loadQRContent(c, id)
+	if code == nil {
+		return
+	}
+	etag := qrETag(content, opts, logoKey)
+	c.Header("ETag", etag)
+	if code.Visibility == models.QRPrivate {
+		// Only the owner and admins may see it; keep it out of shared caches.
+		c.Header("Cache-Control", "private, max-age=86400")
+	} else {
+		c.Header("Cache-Control", "public, max-age=86400")
+	}
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	writeQRImage(c, code, content, opts)
+}
+
+// RenderQRCodeImage is GetQRCodeImage with a one-off logo uploaded as the
+// multipart field "logo" instead of a stored one. The result is not cached.
+func (h *Handler) RenderQRCodeImage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	opts, err := qrOptionsFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	data, ok := readImageUpload(c, "logo", qr.MaxLogoBytes)
+	if !ok {
+		return
+	}
+	if opts.Logo, _, err = qr.DecodeLogo(data); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code, content := h.loadQRContent(c, id)
+	if code == nil {
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	writeQRImage(c, code, content, opts)
+}
+
+func writeQRImage(c *gin.Context, code *models.QRCode, content string, opts qr.Options) {
+	img, err := qr.Render(content, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="qr-%d.%s"`, code.ID, opts.Format))
+	c.Data(http.StatusOK, qr.ContentType(opts.Format), img)
+}
+
+// qrOptionsFromQuery reads rendering options, keeping defaults for missing ones.
+func qrOptionsFromQuery(c *gin.Context) (qr.Options, error) {
+	opts := qr.DefaultOptions()
+	if v := c.Query("format"); v != "" {
+		opts.Format = strings.ToLower(v)
+	}
+	if v := c.Query("ecc"); v != "" {
+		opts.ECC = strings.ToUpper(v)
+	}
+	for name, dst := range map[string]*int{"size": &opts.Size, "margin": &opts.Margin} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return opts, fmt.Errorf("%s must be an integer", name)
+		}
+		*dst = n
+	}
+	for name, dst := range map[string]*color.RGBA{"fg": &opts.Foreground, "bg": &opts.Background} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		col, err := qr.ParseColor(v)
+		if err != nil {
+			return opts, fmt.Errorf("%s: %w", name, err)
+		}
+		*dst = col
+	}
+	if v := c.Query("rounded"); v != "" {
+		rounded, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("rounded must be true or false")
+		}
+		opts.Rounded = rounded
+	}
+	opts.Caption = strings.TrimSpace(c.Query("caption"))
+	return opts, opts.Validate()
+}
+
+func qrETag(content string, opts qr.Options, logoKey string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%s|%d|%s|%s|%t|%s|%s",
+		content, opts.Format, opts.Size, opts.ECC, opts.Margin,
+		qr.Hex(opts.Foreground), qr.Hex(opts.Background), opts.Rounded, opts.Caption, logoKey)))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// UploadQRLogo stores a logo (multipart field "file", optional "name") that
+// branded codes can use with ?logo=<id>.
+func (h *Handler) UploadQRLogo(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	data, ok := readImageUpload(c, "file", qr.MaxLogoBytes)
+	if !ok {
+		return
+	}
+	img, prepared, err := qr.PrepareLogo(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := strings.TrimSpace(c.PostForm("name"))
+	if name == "" {
+		name = "logo"
+	}
+	if len(name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be at most 100 characters"})
+		return
+	}
+
+	logo := &models.QRLogo{Name: name, ContentType: "image/png", Data: prepared, UploadedBy: userID.(int)}
+	if err := h.db.SaveQRLogo(c.Request.Context(), logo); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save logo"})
+		return
+	}
+	h.logos.put(logo.ID, img)
+	c.JSON(http.StatusCreated, gin.H{"data": logo})
+}
+
+func (h *Handler) GetQRLogos(c *gin.Context) {
+	logos, err := h.db.ListQRLogos(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch logos"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": logos, "total": len(logos)})
+}
+
+// GetQRLogoImage serves a stored logo. Logos never change, so it can be
+// cached for long.
+func (h *Handler) GetQRLogoImage(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+	logo, err := h.db.GetQRLogo(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	if logo == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "logo not found"})
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Data(http.StatusOK, logo.ContentType, logo.Data)
+}
+
+// storedLogo returns the decoded logo with the given id, writing the error
+// response and returning nil when it cannot.
+func (h *Handler) storedLogo(c *gin.Context, id int) image.Image {
+	if img, ok := h.logos.get(id); ok {
+		return img
+	}
+	logo, err := h.db.GetQRLogo(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return nil
+	}
+	if logo == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "logo not found"})
+		return nil
+	}
+	img, _, err := qr.DecodeLogo(logo.Data)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "stored logo is invalid"})
+		return nil
+	}
+	h.logos.put(id, img)
+	return img
+}
+
+// maxCachedLogos bounds logoCache; schools keep a handful of logos.
+const maxCachedLogos = 64
+
+// logoCache keeps decoded stored logos by id so codes are not re-decoded on
+// every render. Logos never change after upload, so entries cannot go stale.
+type logoCache struct {
+	mu     sync.Mutex
+	images map[int]image.Image
+}
+
+func (lc *logoCache) get(id int) (image.Image, bool) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	img, ok := lc.images[id]
+	return img, ok
+}
+
+func (lc *logoCache) put(id int, img image.Image) {
+	lc.mu.Lock()
+	defer lc.mu.Unlock()
+	if lc.images == nil || len(lc.images) >= maxCachedLogos {
+		lc.images = map[int]image.Image{}
+	}
+	lc.images[id] = img
+}
+
+// readImageUpload reads one multipart file of at most limit bytes, writing
+// the error response when it cannot.
+func readImageUpload(c *gin.Context, field string, limit int64) ([]byte, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit+64<<10)
+	fh, err := c.FormFile(field)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be an image file of at most %d KB", field, limit>>10)})
+		return nil, false
+	}
+	if fh.Size > limit {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("%s must be at most %d KB", field, limit>>10)})
+		return nil, false
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read upload"})
+		return nil, false
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read upload"})
+		return nil, false
+	}
+	return data, true
+}
+
+// loadQRContent loads a code and the text its image encodes, writing the
+// error response and returning nil when it cannot.
+func (h *Handler) loadQRContent(c *gin.Context, id int) (*models.QRCode, string) {
+	code := h.loadQRCode(c, id)
+	if code == nil {
+		return nil, ""
+	}
+	content, err := h.qrContent(code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "stored payload is invalid"})
+		return nil, ""
+	}
+	return code, content
+}
+
+// loadQRCode fetches a code the caller may see, writing the error response
+// and returning nil when it cannot. Private codes of others look missing.
+func (h *Handler) loadQRCode(c *gin.Context, id int) *models.QRCode {
+	code, err := h.db.GetQRCode(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return nil
+	}
+	if code != nil && code.Visibility == models.QRPrivate {
+		owner, err := h.ownsQRCode(c, code)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+			return nil
+		}
+		if !owner {
+			code = nil
+		}
+	}
+	if code == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "QR code not found"})
+		return nil
+	}
+	h.fillQRCode(code)
+	return code
+}
+
+// loadOwnQRCode is loadQRCode for changes, which only the owner or an admin
+// may make.
+func (h *Handler) loadOwnQRCode(c *gin.Context, id int) *models.QRCode {
+	code := h.loadQRCode(c, id)
+	if code == nil {
+		return nil
+	}
+	owner, err := h.ownsQRCode(c, code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return nil
+	}
+	if !owner {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only the owner or an admin can change this QR code"})
+		return nil
+	}
+	return code
+}
+
+// ownsQRCode reports whether the caller created code or is an admin.
+func (h *Handler) ownsQRCode(c *gin.Context, code *models.QRCode) (bool, error) {
+	viewerID, admin, err := h.qrViewer(c)
+	if err != nil {
+		return false, err
+	}
+	return admin || (viewerID != 0 && code.CreatedBy != nil && *code.CreatedBy == viewerID), nil
+}
+
+// qrViewer returns the caller's id (0 for guests) and whether they are an
+// admin.
+func (h *Handler) qrViewer(c *gin.Context) (int, bool, error) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		return 0, false, nil
+	}
+	if role, ok := c.Get("user_role"); ok {
+		return userID.(int), role == models.RoleAdmin, nil
+	}
+	user, err := h.db.GetUserByID(c.Request.Context(), userID.(int))
+	if err != nil {
+		return 0, false, err
+	}
+	if user == nil {
+		return 0, false, nil
+	}
+	c.Set("user_role", user.Role)
+	return user.ID, user.Role == models.RoleAdmin, nil
+}
+
+// qrContent is the text a code's image encodes.
+func (h *Handler) qrContent(code *models.QRCode) (string, error) {
+	if code.Payload == nil {
+		return code.ShortURL, nil
+	}
+	p, err := qr.ParsePayload(code.Type, code.Payload)
+	if err != nil {
+		return "", err
+	}
+	return p.Text(), nil
+}
+
+// normalizePayload validates payload fields and returns them re-encoded
+// without unknown or untrimmed values.
+func normalizePayload(kind string, raw json.RawMessage) (json.RawMessage, error) {
+	p, err := qr.ParsePayload(kind, raw)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := qr.Encode(p.Text(), "L", 0); err != nil {
+		return nil, fmt.Errorf("payload is too large for a QR code")
+	}
+	return json.Marshal(p)
+}
+
+// fillQRCode sets the fields derived when a code is returned.
+func (h *Handler) fillQRCode(code *models.QRCode) {
+	code.ShortURL = h.publicURL + "/q/" + code.Slug
+	code.Expired = code.ExpiresAt != nil && !time.Now().Before(*code.ExpiresAt)
+}
+
+// deviceType buckets a user agent coarsely; the full string is kept separately.
+func deviceType(ua string) string {
+	ua = strings.ToLower(ua)
+	switch {
+	case ua == "":
+		return "other"
+	case containsAny(ua, "bot", "crawler", "spider", "preview", "facebookexternalhit", "curl", "wget"):
+		return "bot"
+	case containsAny(ua, "ipad", "tablet") || (strings.Contains(ua, "android") && !strings.Contains(ua, "mobile")):
+		return "tablet"
+	case containsAny(ua, "mobile", "iphone", "ipod", "android"):
+		return "mobile"
+	case containsAny(ua, "windows", "macintosh", "x11", "linux", "cros"):
+		return "desktop"
+	}
+	return "other"
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 character.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"edu-web-backend/internal/chatbot"
+	"edu-web-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newReplyToken returns a random secret for the student and the hash we store.
+func newReplyToken() (token, hash string, err error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(buf)
+	return token, hashReplyToken(token), nil
+}
+
+func hashReplyToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AskAnonymousQuestion is public and deliberately ignores any login: the
+// question is stored without a user, and PII is redacted so it cannot identify
+// the student either.
+func (h *Handler) AskAnonymousQuestion(c *gin.Context) {
+	var req struct {
+		Question string `json:"question" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req.Question = strings.TrimSpace(req.Question)
+	if req.Question == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "question cannot be empty"})
+		return
+	}
+	if len(req.Question) > 2000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "question too long (max 2000)"})
+		return
+	}
+
+	question, _ := h.redactor.Redact(req.Question)
+	token, hash, err := newReplyToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create reply token"})
+		return
+	}
+
+	// Judged on what the student wrote, not the redacted copy.
+	urgent := chatbot.IsCrisis(req.Question)
+	q, err := h.db.CreateAnonymousQuestion(c.Request.Context(), question, hash, urgent)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save question"})
+		return
+	}
+
+	resp := gin.H{
+		"data":        gin.H{"id": q.ID, "status": q.Status, "created_at": q.CreatedAt},
+		"reply_token": token,
+		"message":     "Hay luu lai ma nay - day la cach duy nhat de doc cau tra loi cua thay co.",
+	}
+	if urgent {
+		resp["crisis_message"], _ = crisisResponse(c.Request.Context(), h.db, 0, chatbot.DetectLanguage(req.Question))
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetAnonymousReply lets the student poll for the counselor's answer with the
+// reply token. The token is sent in the body so it does not end up in URLs or logs.
+func (h *Handler) GetAnonymousReply(c *gin.Context) {
+	var req struct {
+		ReplyToken string `json:"reply_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	q, err := h.db.GetAnonymousQuestionByToken(c.Request.Context(), hashReplyToken(strings.TrimSpace(req.ReplyToken)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	if q == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "question not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": gin.H{
+		"id":          q.ID,
+		"question":    q.Question,
+		"status":      q.Status,
+		"answer":      q.Answer,
+		"created_at":  q.CreatedAt,
+		"answered_at": q.AnsweredAt,
+	}})
+}
+
+func (h *Handler) ListAnonymousQuestions(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", models.QuestionOpen, models.QuestionClaimed, models.QuestionAnswered, models.QuestionClosed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+	questions, err := h.db.ListAnonymousQuestions(c.Request.Context(), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch questions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": questions, "total": len(questions)})
+}
+
+func (h *Handler) ClaimAnonymousQuestion(c *gin.Context) {
+	counselorID, _ := c.Get("user_id")
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	q, err := h.db.ClaimAnonymousQuestion(c.Request.Context(), id, counselorID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to claim question"})
+		return
+	}
+	if q == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "question is not open"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": q})
+}
+
+func (h *Handler) AnswerAnonymousQuestion(c *gin.Context) {
+	counselorID, _ := c.Get("user_id")
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	var req struct {
+		Answer string `json:"answer" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Answer = strings.TrimSpace(req.Answer)
+	if req.Answer == "" || len(req.Answer) > 5000 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "answer must be 1-5000 characters"})
+		return
+	}
+
+	q, err := h.db.AnswerAnonymousQuestion(c.Request.Context(), id, counselorID.(int), req.Answer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save answer"})
+		return
+	}
+	if q == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "question must be claimed by you before answering"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": q})
+}
+
+func (h *Handler) CloseAnonymousQuestion(c *gin.Context) {
+	counselorID, _ := c.Get("user_id")
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	q, err := h.db.CloseAnonymousQuestion(c.Request.Context(), id, counselorID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to close question"})
+		return
+	}
+	if q == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "question is already closed or claimed by another counselor"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": q})
+}
@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"edu-web-backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxSafetyPlanItems   = 20
+	maxSafetyPlanItemLen = 300
+)
+
+func (h *Handler) GetSafetyPlan(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	plan, err := h.db.GetSafetyPlan(c.Request.Context(), userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	if plan == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "safety plan not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+// SaveSafetyPlan stores the request body as a new version of the caller's plan.
+func (h *Handler) SaveSafetyPlan(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	h.saveSafetyPlan(c, userID.(int), userID.(int))
+}
+
+func (h *Handler) GetSafetyPlanVersions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	versions, err := h.db.GetSafetyPlanVersions(c.Request.Context(), userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch versions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": versions, "total": len(versions)})
+}
+
+// ShareSafetyPlan assigns the counselor who may read and edit the caller's plan.
+func (h *Handler) ShareSafetyPlan(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req struct {
+		CounselorID int `json:"counselor_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	counselor, err := h.db.GetUserByID(c.Request.Context(), req.CounselorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	if counselor == nil || counselor.Role != models.RoleCounselor {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "counselor not found"})
+		return
+	}
+
+	found, err := h.db.SetSafetyPlanCounselor(c.Request.Context(), userID.(int), &req.CounselorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to share safety plan"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "safety plan not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "safety plan shared", "counselor_id": req.CounselorID})
+}
+
+func (h *Handler) UnshareSafetyPlan(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	found, err := h.db.SetSafetyPlanCounselor(c.Request.Context(), userID.(int), nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unshare safety plan"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "safety plan not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "safety plan no longer shared"})
+}
+
+func (h *Handler) GetSharedSafetyPlans(c *gin.Context) {
+	counselorID, _ := c.Get("user_id")
+	plans, err := h.db.GetSharedSafetyPlans(c.Request.Context(), counselorID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch safety plans"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": plans, "total": len(plans)})
+}
+
+// UpdateStudentSafetyPlan lets the assigned counselor save a new version of a
+// student's plan.
+func (h *Handler) UpdateStudentSafetyPlan(c *gin.Context) {
+	counselorID, _ := c.Get("user_id")
+	studentID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil || studentID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	plan, err := h.db.GetSafetyPlan(c.Request.Context(), studentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	if plan == nil || plan.CounselorID == nil || *plan.CounselorID != counselorID.(int) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "safety plan not found"})
+		return
+	}
+	h.saveSafetyPlan(c, studentID, counselorID.(int))
+}
+
+func (h *Handler) saveSafetyPlan(c *gin.Context, studentID, editorID int) {
+	var content models.SafetyPlanContent
+	if err := c.ShouldBindJSON(&content); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := cleanSafetyPlan(&content); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.db.SaveSafetyPlan(c.Request.Context(), studentID, editorID, content)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save safety plan"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": plan})
+}
+
+// cleanSafetyPlan trims every entry, drops empty ones and enforces size limits.
+func cleanSafetyPlan(p *models.SafetyPlanContent) error {
+	lists := map[string]*[]string{
+		"warning_signs":     &p.WarningSigns,
+		"coping_strategies": &p.CopingStrategies,
+		"safe_environment":  &p.SafeEnvironment,
+	}
+	for name, list := range lists {
+		cleaned := []string{}
+		for _, item := range *list {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			if len(item) > maxSafetyPlanItemLen {
+				return fmt.Errorf("%s entries must be at most %d characters", name, maxSafetyPlanItemLen)
+			}
+			cleaned = append(cleaned, item)
+		}
+		if len(cleaned) > maxSafetyPlanItems {
+			return fmt.Errorf("%s can have at most %d entries", name, maxSafetyPlanItems)
+		}
+		*list = cleaned
+	}
+
+	contacts := map[string]*[]models.SafetyContact{
+		"contacts":              &p.Contacts,
+		"professional_contacts": &p.ProfessionalContacts,
+	}
+	for name, list := range contacts {
+		cleaned := []models.SafetyContact{}
+		for _, ct := range *list {
+			ct.Name = strings.TrimSpace(ct.Name)
+			ct.Phone = strings.TrimSpace(ct.Phone)
+			ct.Relation = strings.TrimSpace(ct.Relation)
+			if ct.Name == "" && ct.Phone == "" {
+				continue
+			}
+			if len(ct.Name) > 100 || len(ct.Phone) > 30 || len(ct.Relation) > 100 {
+				return fmt.Errorf("%s entry is too long", name)
+			}
+			cleaned = append(cleaned, ct)
+		}
+		if len(cleaned) > maxSafetyPlanItems {
+			return fmt.Errorf("%s can have at most %d entries", name, maxSafetyPlanItems)
+		}
+		*list = cleaned
+	}
+	return nil
+}
+
+// formatSafetyPlan renders the plan as chatbot text for the crisis path.
+func formatSafetyPlan(p models.SafetyPlanContent, labels safetyPlanLabels) string {
+	var b strings.Builder
+	b.WriteString(labels.title)
+	writeList := func(title string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		b.WriteString("\n\n" + title)
+		for _, item := range items {
+			b.WriteString("\n- " + item)
+		}
+	}
+	writeContacts := func(title string, contacts []models.SafetyContact) {
+		items := make([]string, 0, len(contacts))
+		for _, ct := range contacts {
+			item := ct.Name
+			if ct.Relation != "" {
+				item += " (" + ct.Relation + ")"
+			}
+			if ct.Phone != "" {
+				item += ": " + ct.Phone
+			}
+			items = append(items, item)
+		}
+		writeList(title, items)
+	}
+
+	writeList(labels.warningSigns, p.WarningSigns)
+	writeList(labels.coping, p.CopingStrategies)
+	writeContacts(labels.contacts, p.Contacts)
+	writeContacts(labels.professionals, p.ProfessionalContacts)
+	writeList(labels.environment, p.SafeEnvironment)
+	return b.String()
+}
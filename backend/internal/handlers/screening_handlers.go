@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"edu-web-backend/internal/chatbot"
+	"edu-web-backend/internal/models"
+	"edu-web-backend/internal/screening"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) GetQuestionnaires(c *gin.Context) {
+	list := make([]screening.Questionnaire, 0, len(screening.Codes))
+	for _, code := range screening.Codes {
+		q, _ := screening.Get(code)
+		list = append(list, q)
+	}
+	c.JSON(http.StatusOK, gin.H{"data": list, "total": len(list)})
+}
+
+func (h *Handler) GetQuestionnaire(c *gin.Context) {
+	q, ok := screening.Get(c.Param("code"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "questionnaire not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": q})
+}
+
+func (h *Handler) SubmitScreening(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	q, ok := screening.Get(c.Param("code"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "questionnaire not found"})
+		return
+	}
+
+	var req struct {
+		Answers []int `json:"answers" binding:"required"`
+		// Lang picks the crisis message language; by default it follows the
+		// student's most recent chat.
+		Lang string `json:"lang"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := q.Score(req.Answers)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	saved := &models.ScreeningResult{
+		UserID:        userID.(int),
+		Questionnaire: q.Code,
+		Answers:       req.Answers,
+		Total:         result.Total,
+		Severity:      result.Severity,
+		Crisis:        result.Crisis,
+	}
+	if err := h.db.SaveScreeningResult(c.Request.Context(), saved); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save result"})
+		return
+	}
+
+	resp := gin.H{"data": saved, "result": result}
+	if result.Crisis {
+		resp["message"] = withSafetyPlan(crisisResponse(c.Request.Context(), h.db, userID.(int), h.studentLang(c, userID.(int), req.Lang)))
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+func (h *Handler) GetScreeningResults(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	code := c.Query("questionnaire")
+	if _, ok := screening.Get(code); code != "" && !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown questionnaire"})
+		return
+	}
+	results, err := h.db.GetScreeningResults(c.Request.Context(), userID.(int), code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch results"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": results, "total": len(results)})
+}
+
+// GetScreeningSummary is the counselor view: band counts without individual answers.
+func (h *Handler) GetScreeningSummary(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days <= 0 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+		return
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	summary, err := h.db.GetScreeningSummary(c.Request.Context(), since)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch summary"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": summary, "since": since})
+}
+
+// studentLang is the requested language if valid, else the language of the
+// student's most recent chat, else Vietnamese.
+func (h *Handler) studentLang(c *gin.Context, userID int, requested string) string {
+	if chatbot.IsLanguage(requested) {
+		return requested
+	}
+	lang, err := h.db.GetLastChatLang(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("last chat lang: %v", err)
+	}
+	if chatbot.IsLanguage(lang) {
+		return lang
+	}
+	return chatbot.LangVietnamese
+}
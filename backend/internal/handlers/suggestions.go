@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"edu-web-backend/internal/chatbot"
+	"edu-web-backend/internal/models"
+)
+
+const maxSuggestions = 3
+
+// lowMoodContentTags are used for a recent low check-in without tags: calming
+// content rather than, say, study tips.
+var lowMoodContentTags = []string{"stress", "anxiety"}
+
+// suggestContent picks videos and audios for a chat turn: by the category
+// detected in the message, or else by the student's check-in from the last day.
+func (h *Handler) suggestContent(ctx context.Context, message string, userID int) []models.ContentSuggestion {
+	var terms []string
+	if category := chatbot.DetectCategory(message); category != "" {
+		terms = []string{category}
+	} else if userID > 0 {
+		entries, err := h.db.GetMoodEntriesSince(ctx, userID, time.Now().Add(-24*time.Hour))
+		if err != nil {
+			log.Printf("content suggestions: %v", err)
+		} else if len(entries) > 0 {
+			latest := entries[len(entries)-1]
+			terms = latest.Tags
+			if len(terms) == 0 && latest.Score <= lowMoodScore {
+				terms = lowMoodContentTags
+			}
+		}
+	}
+	if len(terms) == 0 {
+		return []models.ContentSuggestion{}
+	}
+
+	suggestions, err := h.db.GetContentSuggestions(ctx, terms, maxSuggestions)
+	if err != nil {
+		log.Printf("content suggestions: %v", err)
+		return []models.ContentSuggestion{}
+	}
+	return suggestions
+}
+
+// suggestionText lists the suggestions in the reply for clients that do not render cards.
+func suggestionText(suggestions []models.ContentSuggestion, lang string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n" + localeFor(lang).suggestionHeader)
+	for _, s := range suggestions {
+		b.WriteString("\n- " + s.Title + " (" + s.Type + ")")
+	}
+	return b.String()
+}
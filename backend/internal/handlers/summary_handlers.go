@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"edu-web-backend/internal/models"
+	"edu-web-backend/internal/summary"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShareChatSummary is the student's referral consent: it summarizes one of
+// their Buddy sessions and sends it to the chosen counselor as a direct
+// message, which opens a thread between them.
+func (h *Handler) ShareChatSummary(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+	session := h.ownedSession(c, c.Param("session_id"))
+	if session == nil {
+		return
+	}
+
+	var req struct {
+		CounselorID int `json:"counselor_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	counselor, err := h.db.GetUserByID(ctx, req.CounselorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database error"})
+		return
+	}
+	if counselor == nil || counselor.Role != models.RoleCounselor {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "counselor not found"})
+		return
+	}
+
+	messages, err := h.db.GetSessionTranscript(ctx, session.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch messages"})
+		return
+	}
+	if len(messages) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "session has no messages"})
+		return
+	}
+	exercises, err := h.db.GetExerciseLog(ctx, session.SessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch exercises"})
+		return
+	}
+
+	sum, err := h.summarizer.Summarize(summary.Conversation{SessionID: session.SessionID, Messages: messages, Exercises: exercises})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to summarize session"})
+		return
+	}
+
+	share := &models.ChatSummaryShare{
+		SessionID:   session.SessionID,
+		StudentID:   userID.(int),
+		CounselorID: req.CounselorID,
+		Summary:     sum,
+	}
+	if err := h.db.ShareChatSummary(ctx, share, summary.Text(sum, h.loc)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to share summary"})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"data": share})
+}
+
+func (h *Handler) GetChatSummaryShares(c *gin.Context) {
+	counselorID, _ := c.Get("user_id")
+	shares, err := h.db.GetChatSummaryShares(c.Request.Context(), counselorID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to fetch summaries"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"data": shares, "total": len(shares)})
+}
@@ -0,0 +1,534 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Info is what Probe learns from a file's container headers. Zero fields
+// were not found.
+type Info struct {
+	Duration time.Duration
+	Bitrate  int    // average bits per second over the whole file
+	Codec    string // e.g. "mp3", "aac" or "h264, aac" for a video with sound
+	Width    int
+	Height   int
+}
+
+// ErrNoMetadata is returned for formats Probe cannot read durations from.
+var ErrNoMetadata = errors.New("media metadata not available for this format")
+
+// Probe reads the metadata of a file Sniff identified as f. Only headers and
+// small tables are read, never the media data itself, so probing a long
+// lecture is cheap.
+func Probe(r io.ReadSeeker, size int64, f Format) (Info, error) {
+	var info Info
+	var err error
+	switch f.ContentType {
+	case "audio/mpeg":
+		info, err = probeMP3(r, size)
+	case "audio/mp4", "video/mp4", "video/quicktime":
+		info, err = probeMP4(r, size)
+	case "audio/ogg":
+		info, err = probeOgg(r, size)
+	case "audio/flac":
+		info, err = probeFLAC(r)
+	case "audio/wav":
+		info, err = probeWAV(r, size)
+	default:
+		return Info{}, ErrNoMetadata
+	}
+	if err != nil {
+		return Info{}, fmt.Errorf("probe %s: %w", f.ContentType, err)
+	}
+	if info.Bitrate == 0 && info.Duration > 0 {
+		info.Bitrate = int(float64(size*8) / info.Duration.Seconds())
+	}
+	return info, nil
+}
+
+// FormatDuration writes seconds as m:ss, or h:mm:ss from an hour up.
+func FormatDuration(seconds int) string {
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// seconds converts a count of units at rate per second to a duration.
+func seconds(units uint64, rate uint64) time.Duration {
+	if rate == 0 {
+		return 0
+	}
+	d := float64(units) / float64(rate) * float64(time.Second)
+	if d > math.MaxInt64 {
+		return 0
+	}
+	return time.Duration(d)
+}
+
+func readAt(r io.ReadSeeker, off int64, buf []byte) (int, error) {
+	if _, err := r.Seek(off, io.SeekStart); err != nil {
+		return 0, err
+	}
+	n, err := io.ReadFull(r, buf)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = nil
+	}
+	return n, err
+}
+
+// MP3
+
+var mp3Bitrates = [2][3][15]int{
+	{ // MPEG-1, layers I, II, III
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	},
+	{ // MPEG-2 and 2.5
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	},
+}
+
+type mp3Frame struct {
+	mpeg1      bool
+	layer      int // 1-3
+	bitrate    int // bits per second
+	sampleRate int
+	samples    int // per frame
+	length     int // bytes
+	mono       bool
+}
+
+func parseMP3Frame(h []byte) (mp3Frame, bool) {
+	if len(h) < 4 || !mpegFrame(h) {
+		return mp3Frame{}, false
+	}
+	version := h[1] >> 3 & 3 // 0 = 2.5, 2 = 2, 3 = 1
+	layer := 4 - int(h[1]>>1&3)
+	brIndex, srIndex := int(h[2]>>4), int(h[2]>>2&3)
+	if brIndex == 0 || brIndex == 15 || srIndex == 3 {
+		return mp3Frame{}, false
+	}
+	f := mp3Frame{mpeg1: version == 3, layer: layer, mono: h[3]>>6 == 3}
+	table := 1
+	if f.mpeg1 {
+		table = 0
+	}
+	f.bitrate = mp3Bitrates[table][layer-1][brIndex] * 1000
+	f.sampleRate = [3]int{44100, 48000, 32000}[srIndex]
+	switch version {
+	case 2:
+		f.sampleRate /= 2
+	case 0:
+		f.sampleRate /= 4
+	}
+	switch {
+	case layer == 1:
+		f.samples = 384
+	case layer == 3 && !f.mpeg1:
+		f.samples = 576
+	default:
+		f.samples = 1152
+	}
+	padding := int(h[2] >> 1 & 1)
+	if layer == 1 {
+		padding *= 4
+	}
+	f.length = f.samples/8*f.bitrate/f.sampleRate + padding
+	return f, true
+}
+
+// probeMP3 finds the first frame after any ID3v2 tag. A Xing, Info or VBRI
+// header in that frame gives the frame count of VBR files; otherwise the
+// file is taken as constant bitrate.
+func probeMP3(r io.ReadSeeker, size int64) (Info, error) {
+	var start int64
+	hdr := make([]byte, 10)
+	if _, err := readAt(r, 0, hdr); err != nil {
+		return Info{}, err
+	}
+	if bytes.HasPrefix(hdr, []byte("ID3")) {
+		start = 10 + (int64(hdr[6]&0x7F)<<21 | int64(hdr[7]&0x7F)<<14 | int64(hdr[8]&0x7F)<<7 | int64(hdr[9]&0x7F))
+		if hdr[5]&0x10 != 0 {
+			start += 10 // footer
+		}
+	}
+
+	// Tag padding and junk may come before the first frame; require a
+	// second frame right after a candidate to avoid false syncs.
+	buf := make([]byte, 64<<10)
+	n, err := readAt(r, start, buf)
+	if err != nil {
+		return Info{}, err
+	}
+	buf = buf[:n]
+	var f mp3Frame
+	pos := -1
+	for i := 0; i+4 <= len(buf); i++ {
+		cand, ok := parseMP3Frame(buf[i:])
+		if !ok {
+			continue
+		}
+		if next := i + cand.length; next+4 <= len(buf) {
+			if _, ok := parseMP3Frame(buf[next:]); !ok {
+				continue
+			}
+		}
+		f, pos = cand, i
+		break
+	}
+	if pos < 0 {
+		return Info{}, errors.New("no MPEG audio frame found")
+	}
+	start += int64(pos)
+	frame := buf[pos:]
+
+	audioBytes := size - start
+	tail := make([]byte, 3)
+	if size >= 128 {
+		if _, err := readAt(r, size-128, tail); err == nil && string(tail) == "TAG" {
+			audioBytes -= 128
+		}
+	}
+
+	codec := [4]string{"", "mp1", "mp2", "mp3"}[f.layer]
+	side := 32
+	switch {
+	case f.mpeg1 && f.mono, !f.mpeg1 && !f.mono:
+		side = 17
+	case !f.mpeg1 && f.mono:
+		side = 9
+	}
+	var frames uint32
+	if x := 4 + side; len(frame) >= x+12 && (string(frame[x:x+4]) == "Xing" || string(frame[x:x+4]) == "Info") {
+		if binary.BigEndian.Uint32(frame[x+4:])&1 != 0 {
+			frames = binary.BigEndian.Uint32(frame[x+8:])
+		}
+	} else if len(frame) >= 36+18 && string(frame[36:40]) == "VBRI" {
+		frames = binary.BigEndian.Uint32(frame[36+14:])
+	}
+	if frames > 0 {
+		d := seconds(uint64(frames)*uint64(f.samples), uint64(f.sampleRate))
+		return Info{Duration: d, Bitrate: int(float64(audioBytes*8) / d.Seconds()), Codec: codec}, nil
+	}
+	return Info{Duration: seconds(uint64(audioBytes)*8, uint64(f.bitrate)), Bitrate: f.bitrate, Codec: codec}, nil
+}
+
+// MP4, M4A and QuickTime
+
+var mp4Codecs = map[string]string{
+	"avc1": "h264", "avc3": "h264", "hvc1": "hevc", "hev1": "hevc",
+	"av01": "av1", "vp09": "vp9", "mp4v": "mpeg4",
+	"mp4a": "aac", "ac-3": "ac3", "ec-3": "eac3", "Opus": "opus",
+	"alac": "alac", "fLaC": "flac", ".mp3": "mp3",
+}
+
+type mp4Box struct {
+	typ         string
+	start, end  int64 // payload
+	headerBytes int
+}
+
+// mp4Boxes lists the boxes between off and end.
+func mp4Boxes(r io.ReadSeeker, off, end int64) ([]mp4Box, error) {
+	var boxes []mp4Box
+	hdr := make([]byte, 16)
+	for off+8 <= end {
+		if n, err := readAt(r, off, hdr); err != nil || n < 8 {
+			return boxes, err
+		}
+		size := int64(binary.BigEndian.Uint32(hdr))
+		b := mp4Box{typ: string(hdr[4:8]), headerBytes: 8}
+		switch size {
+		case 0:
+			size = end - off
+		case 1:
+			size = int64(binary.BigEndian.Uint64(hdr[8:]))
+			b.headerBytes = 16
+		}
+		if size < int64(b.headerBytes) || off+size > end {
+			// Truncated uploads still report what was read so far.
+			return boxes, nil
+		}
+		b.start, b.end = off+int64(b.headerBytes), off+size
+		boxes = append(boxes, b)
+		off += size
+	}
+	return boxes, nil
+}
+
+func findBox(boxes []mp4Box, typ string) (mp4Box, bool) {
+	for _, b := range boxes {
+		if b.typ == typ {
+			return b, true
+		}
+	}
+	return mp4Box{}, false
+}
+
+func readBox(r io.ReadSeeker, b mp4Box, limit int) ([]byte, error) {
+	buf := make([]byte, min(b.end-b.start, int64(limit)))
+	n, err := readAt(r, b.start, buf)
+	return buf[:n], err
+}
+
+// fullBoxTime reads the timescale and duration of an mvhd or mdhd box.
+func fullBoxTime(p []byte) (timescale, duration uint64, ok bool) {
+	if len(p) >= 32 && p[0] == 1 {
+		return uint64(binary.BigEndian.Uint32(p[20:])), binary.BigEndian.Uint64(p[24:]), true
+	}
+	if len(p) >= 20 && p[0] == 0 {
+		return uint64(binary.BigEndian.Uint32(p[12:])), uint64(binary.BigEndian.Uint32(p[16:])), true
+	}
+	return 0, 0, false
+}
+
+func probeMP4(r io.ReadSeeker, size int64) (Info, error) {
+	top, err := mp4Boxes(r, 0, size)
+	if err != nil {
+		return Info{}, err
+	}
+	moov, ok := findBox(top, "moov")
+	if !ok {
+		return Info{}, errors.New("no moov box")
+	}
+	boxes, err := mp4Boxes(r, moov.start, moov.end)
+	if err != nil {
+		return Info{}, err
+	}
+
+	var info Info
+	if mvhd, ok := findBox(boxes, "mvhd"); ok {
+		p, err := readBox(r, mvhd, 32)
+		if err != nil {
+			return Info{}, err
+		}
+		if scale, dur, ok := fullBoxTime(p); ok {
+			info.Duration = seconds(dur, scale)
+		}
+	}
+
+	var videoCodec, audioCodecs []string
+	for _, trak := range boxes {
+		if trak.typ != "trak" {
+			continue
+		}
+		t, err := probeMP4Track(r, trak)
+		if err != nil {
+			return Info{}, err
+		}
+		switch t.handler {
+		case "vide":
+			if info.Width == 0 {
+				info.Width, info.Height = t.width, t.height
+			}
+			videoCodec = append(videoCodec, t.codec)
+		case "soun":
+			audioCodecs = append(audioCodecs, t.codec)
+		}
+		// Fragmented files may leave mvhd empty; fall back to the longest track.
+		if t.duration > info.Duration && info.Duration == 0 {
+			info.Duration = t.duration
+		}
+	}
+	info.Codec = strings.Join(append(videoCodec, audioCodecs...), ", ")
+	return info, nil
+}
+
+type mp4Track struct {
+	handler, codec string
+	width, height  int
+	duration       time.Duration
+}
+
+func probeMP4Track(r io.ReadSeeker, trak mp4Box) (mp4Track, error) {
+	var t mp4Track
+	boxes, err := mp4Boxes(r, trak.start, trak.end)
+	if err != nil {
+		return t, err
+	}
+	if tkhd, ok := findBox(boxes, "tkhd"); ok {
+		p, err := readBox(r, tkhd, 92)
+		if err != nil {
+			return t, err
+		}
+		off := 76
+		if len(p) > 0 && p[0] == 1 {
+			off = 88
+		}
+		if len(p) >= off+8 {
+			t.width = int(binary.BigEndian.Uint32(p[off:]) >> 16)
+			t.height = int(binary.BigEndian.Uint32(p[off+4:]) >> 16)
+		}
+	}
+	mdia, ok := findBox(boxes, "mdia")
+	if !ok {
+		return t, nil
+	}
+	if boxes, err = mp4Boxes(r, mdia.start, mdia.end); err != nil {
+		return t, err
+	}
+	if mdhd, ok := findBox(boxes, "mdhd"); ok {
+		p, err := readBox(r, mdhd, 32)
+		if err != nil {
+			return t, err
+		}
+		if scale, dur, ok := fullBoxTime(p); ok {
+			t.duration = seconds(dur, scale)
+		}
+	}
+	if hdlr, ok := findBox(boxes, "hdlr"); ok {
+		p, err := readBox(r, hdlr, 12)
+		if err != nil {
+			return t, err
+		}
+		if len(p) >= 12 {
+			t.handler = string(p[8:12])
+		}
+	}
+	// The sample description is in mdia/minf/stbl/stsd.
+	box := mdia
+	for _, typ := range []string{"minf", "stbl", "stsd"} {
+		children, err := mp4Boxes(r, box.start, box.end)
+		if err != nil {
+			return t, err
+		}
+		if box, ok = findBox(children, typ); !ok {
+			return t, nil
+		}
+	}
+	p, err := readBox(r, box, 16)
+	if err != nil {
+		return t, err
+	}
+	if len(p) >= 16 {
+		format := string(p[12:16])
+		if t.codec = mp4Codecs[format]; t.codec == "" {
+			t.codec = strings.TrimSpace(format)
+		}
+	}
+	return t, nil
+}
+
+// Ogg Vorbis and Opus
+
+const oggTail = 64 << 10
+
+func probeOgg(r io.ReadSeeker, size int64) (Info, error) {
+	head := make([]byte, 27+255+64)
+	n, err := readAt(r, 0, head)
+	if err != nil {
+		return Info{}, err
+	}
+	head = head[:n]
+	if len(head) < 28 || string(head[:4]) != "OggS" {
+		return Info{}, errors.New("not an Ogg page")
+	}
+	serial := binary.LittleEndian.Uint32(head[14:])
+	body := 27 + int(head[26])
+	if len(head) < body+20 {
+		return Info{}, errors.New("short Ogg header")
+	}
+	packet := head[body:]
+
+	var info Info
+	var rate, preSkip uint64
+	switch {
+	case len(packet) >= 24 && string(packet[:7]) == "\x01vorbis":
+		info.Codec = "vorbis"
+		rate = uint64(binary.LittleEndian.Uint32(packet[12:]))
+	case len(packet) >= 16 && string(packet[:8]) == "OpusHead":
+		// Opus granule positions always count 48 kHz samples.
+		info.Codec = "opus"
+		rate = 48000
+		preSkip = uint64(binary.LittleEndian.Uint16(packet[10:]))
+	case len(packet) >= 5 && string(packet[:5]) == "\x7fFLAC":
+		info.Codec = "flac"
+		return info, nil
+	default:
+		return Info{}, errors.New("unknown Ogg codec")
+	}
+
+	// The granule position of the stream's last page is its length in
+	// samples. Pages whose granule is -1 finish no packet; look further back.
+	tailStart := max(size-oggTail, 0)
+	tail := make([]byte, size-tailStart)
+	if n, err = readAt(r, tailStart, tail); err != nil {
+		return Info{}, err
+	}
+	tail = tail[:n]
+	for i := bytes.LastIndex(tail, []byte("OggS")); i >= 0; i = bytes.LastIndex(tail[:i], []byte("OggS")) {
+		if len(tail) < i+27 || tail[i+4] != 0 || binary.LittleEndian.Uint32(tail[i+14:]) != serial {
+			continue
+		}
+		granule := binary.LittleEndian.Uint64(tail[i+6:])
+		if granule == math.MaxUint64 {
+			continue
+		}
+		if granule > preSkip {
+			info.Duration = seconds(granule-preSkip, rate)
+		}
+		break
+	}
+	return info, nil
+}
+
+// FLAC and WAV
+
+func probeFLAC(r io.ReadSeeker) (Info, error) {
+	p := make([]byte, 8+34)
+	if n, err := readAt(r, 0, p); err != nil || n < len(p) {
+		return Info{}, errors.New("short FLAC header")
+	}
+	if p[4]&0x7F != 0 {
+		return Info{}, errors.New("FLAC STREAMINFO missing")
+	}
+	si := p[8:]
+	rate := uint64(si[10])<<12 | uint64(si[11])<<4 | uint64(si[12])>>4
+	total := uint64(si[13]&0x0F)<<32 | uint64(binary.BigEndian.Uint32(si[14:]))
+	return Info{Duration: seconds(total, rate), Codec: "flac"}, nil
+}
+
+func probeWAV(r io.ReadSeeker, size int64) (Info, error) {
+	var info Info
+	var byteRate uint64
+	hdr := make([]byte, 24)
+	for off := int64(12); off+8 <= size; {
+		n, err := readAt(r, off, hdr)
+		if err != nil || n < 8 {
+			return info, err
+		}
+		id, length := string(hdr[:4]), int64(binary.LittleEndian.Uint32(hdr[4:]))
+		switch id {
+		case "fmt ":
+			if n < 24 {
+				return info, errors.New("short fmt chunk")
+			}
+			info.Codec = "pcm"
+			if format := binary.LittleEndian.Uint16(hdr[8:]); format != 1 && format != 0xFFFE {
+				info.Codec = fmt.Sprintf("wav 0x%04x", format)
+			}
+			byteRate = uint64(binary.LittleEndian.Uint32(hdr[16:]))
+			info.Bitrate = int(byteRate * 8)
+		case "data":
+			// Streaming writers may leave the size at 0 or too large.
+			if length == 0 || off+8+length > size {
+				length = size - off - 8
+			}
+			info.Duration = seconds(uint64(length), byteRate)
+			return info, nil
+		}
+		off += 8 + length + length%2
+	}
+	return info, errors.New("no data chunk")
+}
@@ -0,0 +1,118 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// mp3File is two 417-byte MPEG-1 layer III frames at 128 kbit/s and
+// 44.1 kHz, stereo, with tag written at offset 36 of the first frame, where
+// Xing and VBRI headers live.
+func mp3File(tag []byte) []byte {
+	frame := func() []byte {
+		f := make([]byte, 417)
+		copy(f, []byte{0xFF, 0xFB, 0x90, 0x00})
+		return f
+	}
+	first := frame()
+	copy(first[36:], tag)
+	return cat(first, frame())
+}
+
+func cat(parts ...[]byte) []byte { return bytes.Join(parts, nil) }
+
+func be32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+func box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	return append(append(be32(uint32(8+len(body))), typ...), body...)
+}
+
+func mvhd0(timescale, duration uint32) []byte {
+	p := make([]byte, 20)
+	binary.BigEndian.PutUint32(p[12:], timescale)
+	binary.BigEndian.PutUint32(p[16:], duration)
+	return box("mvhd", p)
+}
+
+func mvhd1(timescale uint32, duration uint64) []byte {
+	p := make([]byte, 32)
+	p[0] = 1
+	binary.BigEndian.PutUint32(p[20:], timescale)
+	binary.BigEndian.PutUint64(p[24:], duration)
+	return box("mvhd", p)
+}
+
+// oggPage is a page of stream 7 holding one packet.
+func oggPage(headerType byte, granule uint64, packet []byte) []byte {
+	p := []byte("OggS\x00")
+	p = append(p, headerType)
+	p = binary.LittleEndian.AppendUint64(p, granule)
+	p = binary.LittleEndian.AppendUint32(p, 7)
+	p = append(p, make([]byte, 8)...) // sequence and checksum
+	p = append(p, 1, byte(len(packet)))
+	return append(p, packet...)
+}
+
+func opusHead(preSkip uint16) []byte {
+	p := []byte("OpusHead\x01\x02")
+	p = binary.LittleEndian.AppendUint16(p, preSkip)
+	p = binary.LittleEndian.AppendUint32(p, 48000)
+	return append(p, 0, 0, 0)
+}
+
+func TestProbe(t *testing.T) {
+	xing := cat([]byte("Xing"), be32(1), be32(100))
+	vbri := cat([]byte("VBRI"), make([]byte, 10), be32(200))
+	ftyp := box("ftyp", []byte("isom\x00\x00\x02\x00"))
+	opus := cat(oggPage(2, 0, opusHead(312)), oggPage(0, 0, []byte("OpusTags")), oggPage(4, 3*48000+312, make([]byte, 40)))
+	truncated := cat(ftyp, box("moov", mvhd0(1000, 5000)))
+	binary.BigEndian.PutUint32(truncated[len(ftyp):], 4096)
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		duration    time.Duration
+		bitrate     int
+		codec       string
+		wantErr     bool
+	}{
+		{name: "mp3 xing", contentType: "audio/mpeg", data: mp3File(xing),
+			// 100 frames of 1152 samples at 44.1 kHz.
+			duration: 2612244897, bitrate: 834 * 8 * 44100 / 115200, codec: "mp3"},
+		{name: "mp3 vbri", contentType: "audio/mpeg", data: mp3File(vbri),
+			duration: 5224489795, bitrate: 834 * 8 * 44100 / 230400, codec: "mp3"},
+		{name: "mp3 cbr", contentType: "audio/mpeg", data: mp3File(nil),
+			duration: 834 * 8 * time.Second / 128000, bitrate: 128000, codec: "mp3"},
+		{name: "mvhd v0", contentType: "video/mp4", data: cat(ftyp, box("moov", mvhd0(1000, 5000))),
+			duration: 5 * time.Second, bitrate: (len(ftyp) + 8 + 28) * 8 / 5},
+		{name: "mvhd v1", contentType: "audio/mp4", data: cat(ftyp, box("moov", mvhd1(44100, 44100*90))),
+			duration: 90 * time.Second, bitrate: (len(ftyp) + 40 + 8) * 8 / 90},
+		{name: "opus pre-skip", contentType: "audio/ogg", data: opus,
+			duration: 3 * time.Second, bitrate: len(opus) * 8 / 3, codec: "opus"},
+		{name: "truncated moov", contentType: "video/mp4", data: truncated, wantErr: true},
+		{name: "truncated trak", contentType: "video/mp4",
+			data:     cat(ftyp, box("moov", mvhd0(600, 1200), be32(64), []byte("trak"))),
+			duration: 2 * time.Second, bitrate: (len(ftyp) + 8 + 28 + 8) * 8 / 2},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := Probe(bytes.NewReader(tc.data), int64(len(tc.data)), Format{ContentType: tc.contentType})
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Probe = %+v, want an error", info)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Duration != tc.duration || info.Bitrate != tc.bitrate || info.Codec != tc.codec {
+				t.Errorf("Probe = %+v, want duration %v, bitrate %d, codec %q", info, tc.duration, tc.bitrate, tc.codec)
+			}
+		})
+	}
+}
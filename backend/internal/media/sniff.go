@@ -0,0 +1,68 @@
+// Package media recognizes the audio and video files staff upload to the
+// media library.
+package media
+
+import (
+	"bytes"
+	"errors"
+)
+
+const (
+	KindVideo = "video"
+	KindAudio = "audio"
+)
+
+// SniffLen is how many leading bytes Sniff needs.
+const SniffLen = 512
+
+// Format is a recognized container.
+type Format struct {
+	ContentType string
+	Kind        string
+	Ext         string
+}
+
+// ErrUnsupported is returned for files that are not a supported audio or
+// video container.
+var ErrUnsupported = errors.New("file must be MP3, M4A, AAC, OGG, WAV, FLAC, MP4 or WebM")
+
+// Sniff identifies a file from its first bytes. The name and the type the
+// browser claims are ignored: both are easy to get wrong, and the type served
+// back to every visitor must match the content.
+func Sniff(head []byte) (Format, error) {
+	switch {
+	case bytes.HasPrefix(head, []byte("ID3")), mpegFrame(head):
+		return Format{"audio/mpeg", KindAudio, ".mp3"}, nil
+	case adtsFrame(head):
+		return Format{"audio/aac", KindAudio, ".aac"}, nil
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return Format{"audio/ogg", KindAudio, ".ogg"}, nil
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return Format{"audio/flac", KindAudio, ".flac"}, nil
+	case len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		return Format{"audio/wav", KindAudio, ".wav"}, nil
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return Format{"video/webm", KindVideo, ".webm"}, nil
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		switch string(head[8:12]) {
+		case "M4A ", "M4B ", "M4P ":
+			return Format{"audio/mp4", KindAudio, ".m4a"}, nil
+		case "qt  ":
+			return Format{"video/quicktime", KindVideo, ".mov"}, nil
+		}
+		return Format{"video/mp4", KindVideo, ".mp4"}, nil
+	}
+	return Format{}, ErrUnsupported
+}
+
+// mpegFrame reports an MPEG audio frame header without an ID3 tag: 11 sync
+// bits, then a valid version and layer.
+func mpegFrame(b []byte) bool {
+	return len(b) >= 2 && b[0] == 0xFF && b[1]&0xE0 == 0xE0 && b[1]&0x18 != 0x08 && b[1]&0x06 != 0
+}
+
+// adtsFrame reports an AAC ADTS header, which shares the sync bits but has
+// layer 0.
+func adtsFrame(b []byte) bool {
+	return len(b) >= 2 && b[0] == 0xFF && b[1]&0xF6 == 0xF0
+}
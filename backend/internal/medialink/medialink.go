@@ -0,0 +1,154 @@
+// Package medialink turns the Google Drive and YouTube links staff paste into
+// the URLs the site needs: a canonical link to open, an embeddable player
+// URL and a thumbnail.
+package medialink
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderDrive   = "drive"
+	ProviderYouTube = "youtube"
+)
+
+// Link is a recognized media link.
+type Link struct {
+	Provider  string `json:"provider"`
+	ID        string `json:"id"`
+	URL       string `json:"url"`       // canonical page to open in a new tab
+	EmbedURL  string `json:"embed_url"` // for an iframe
+	Thumbnail string `json:"thumbnail"`
+}
+
+// ErrFolder is returned for Drive folder links: Drive only embeds single
+// files, so each file has to be shared and added on its own.
+var ErrFolder = errors.New("Google Drive folder links cannot be embedded; share the file itself and use its link (drive.google.com/file/d/...)")
+
+// ErrUnsupported is returned for links that are neither Drive files nor
+// YouTube videos.
+var ErrUnsupported = errors.New("link must be a Google Drive file or a YouTube video")
+
+var (
+	driveID   = regexp.MustCompile(`^[A-Za-z0-9_-]{10,}$`)
+	youtubeID = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+)
+
+// Normalize recognizes Drive file, folder, open?id= and uc?id= links and
+// YouTube watch, youtu.be, embed, shorts and live links. An open?id= link
+// may name a folder too; that cannot be told from the URL, so it is taken
+// as a file.
+func Normalize(raw string) (Link, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return Link{}, ErrUnsupported
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	host = strings.TrimPrefix(host, "m.")
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+
+	switch host {
+	case "drive.google.com", "docs.google.com":
+		return drive(u, parts)
+	case "youtube.com", "youtube-nocookie.com", "music.youtube.com":
+		return youtube(u, parts)
+	case "youtu.be":
+		return youtubeLink(parts[0], u.Query())
+	}
+	return Link{}, ErrUnsupported
+}
+
+func drive(u *url.URL, parts []string) (Link, error) {
+	// Drop account switchers: /u/0/file/d/... and /drive/u/1/folders/...
+	if len(parts) >= 2 && parts[0] == "u" {
+		parts = parts[2:]
+	}
+	if len(parts) >= 3 && parts[0] == "drive" && parts[1] == "u" {
+		parts = append([]string{"drive"}, parts[3:]...)
+	}
+
+	switch {
+	case len(parts) >= 3 && parts[0] == "drive" && parts[1] == "folders",
+		len(parts) >= 1 && (parts[0] == "folderview" || parts[0] == "embeddedfolderview"):
+		return Link{}, ErrFolder
+	case len(parts) >= 3 && parts[0] == "file" && parts[1] == "d":
+		return driveLink(parts[2])
+	case len(parts) == 1 && (parts[0] == "open" || parts[0] == "uc" || parts[0] == "thumbnail"):
+		return driveLink(u.Query().Get("id"))
+	}
+	return Link{}, ErrUnsupported
+}
+
+func driveLink(id string) (Link, error) {
+	if !driveID.MatchString(id) {
+		return Link{}, fmt.Errorf("Google Drive link has no valid file id")
+	}
+	return Link{
+		Provider:  ProviderDrive,
+		ID:        id,
+		URL:       "https://drive.google.com/file/d/" + id + "/view",
+		EmbedURL:  "https://drive.google.com/file/d/" + id + "/preview",
+		Thumbnail: "https://drive.google.com/thumbnail?id=" + id + "&sz=w640",
+	}, nil
+}
+
+func youtube(u *url.URL, parts []string) (Link, error) {
+	switch {
+	case len(parts) == 1 && parts[0] == "watch":
+		return youtubeLink(u.Query().Get("v"), u.Query())
+	case len(parts) >= 2 && (parts[0] == "embed" || parts[0] == "shorts" || parts[0] == "live" || parts[0] == "v"):
+		return youtubeLink(parts[1], u.Query())
+	case len(parts) >= 1 && parts[0] == "playlist":
+		return Link{}, fmt.Errorf("YouTube playlist links are not supported; use the link of one video")
+	}
+	return Link{}, ErrUnsupported
+}
+
+// youtubeLink builds the links for a video, keeping a start time given as
+// t= or start= (seconds or 1h2m3s).
+func youtubeLink(id string, q url.Values) (Link, error) {
+	if !youtubeID.MatchString(id) {
+		return Link{}, fmt.Errorf("YouTube link has no valid video id")
+	}
+	l := Link{
+		Provider: ProviderYouTube,
+		ID:       id,
+		URL:      "https://www.youtube.com/watch?v=" + id,
+		// The privacy-enhanced player sets no cookies until the video plays.
+		EmbedURL:  "https://www.youtube-nocookie.com/embed/" + id,
+		Thumbnail: "https://i.ytimg.com/vi/" + id + "/hqdefault.jpg",
+	}
+	start := q.Get("t")
+	if start == "" {
+		start = q.Get("start")
+	}
+	if secs := parseStart(start); secs > 0 {
+		l.URL += "&t=" + strconv.Itoa(secs)
+		l.EmbedURL += "?start=" + strconv.Itoa(secs)
+	}
+	return l, nil
+}
+
+func parseStart(s string) int {
+	if s == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(strings.TrimSuffix(s, "s")); err == nil && n >= 0 {
+		return n
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return int(d.Seconds())
+}
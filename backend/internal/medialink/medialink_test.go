@@ -0,0 +1,92 @@
+package medialink
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	const fileID = "1AbC-dEf_GhIjKlMn"
+	const videoID = "dQw4w9WgXcQ"
+	drive := Link{
+		Provider:  ProviderDrive,
+		ID:        fileID,
+		URL:       "https://drive.google.com/file/d/" + fileID + "/view",
+		EmbedURL:  "https://drive.google.com/file/d/" + fileID + "/preview",
+		Thumbnail: "https://drive.google.com/thumbnail?id=" + fileID + "&sz=w640",
+	}
+	video := Link{
+		Provider:  ProviderYouTube,
+		ID:        videoID,
+		URL:       "https://www.youtube.com/watch?v=" + videoID,
+		EmbedURL:  "https://www.youtube-nocookie.com/embed/" + videoID,
+		Thumbnail: "https://i.ytimg.com/vi/" + videoID + "/hqdefault.jpg",
+	}
+	videoAt := func(secs string) Link {
+		l := video
+		l.URL += "&t=" + secs
+		l.EmbedURL += "?start=" + secs
+		return l
+	}
+
+	tests := []struct {
+		name    string
+		raw     string
+		want    Link
+		wantErr bool
+		errIs   error
+	}{
+		{name: "drive file view", raw: "https://drive.google.com/file/d/" + fileID + "/view?usp=sharing", want: drive},
+		{name: "drive without scheme", raw: "  drive.google.com/file/d/" + fileID + "/preview ", want: drive},
+		{name: "drive account switcher", raw: "https://drive.google.com/u/1/file/d/" + fileID + "/view", want: drive},
+		{name: "drive open", raw: "https://drive.google.com/open?id=" + fileID, want: drive},
+		{name: "drive download", raw: "https://drive.google.com/uc?id=" + fileID + "&export=download", want: drive},
+		{name: "docs download", raw: "https://docs.google.com/uc?export=download&id=" + fileID, want: drive},
+		{name: "drive folder", raw: "https://drive.google.com/drive/folders/1XyZ-folder_id0", wantErr: true, errIs: ErrFolder},
+		{name: "drive folder with account", raw: "https://drive.google.com/drive/u/0/folders/1XyZ-folder_id0", wantErr: true, errIs: ErrFolder},
+		{name: "drive folder view", raw: "https://drive.google.com/embeddedfolderview?id=1XyZ-folder_id0", wantErr: true, errIs: ErrFolder},
+		{name: "drive short id", raw: "https://drive.google.com/file/d/abc/view", wantErr: true},
+		{name: "drive open without id", raw: "https://drive.google.com/open", wantErr: true},
+
+		{name: "youtube watch", raw: "https://www.youtube.com/watch?v=" + videoID + "&list=RD", want: video},
+		{name: "youtube mobile", raw: "https://m.youtube.com/watch?v=" + videoID, want: video},
+		{name: "youtu.be", raw: "youtu.be/" + videoID, want: video},
+		{name: "youtube embed", raw: "https://www.youtube-nocookie.com/embed/" + videoID, want: video},
+		{name: "youtube shorts", raw: "https://youtube.com/shorts/" + videoID, want: video},
+		{name: "youtube live", raw: "https://www.youtube.com/live/" + videoID + "?si=x", want: video},
+		{name: "youtube music", raw: "https://music.youtube.com/watch?v=" + videoID, want: video},
+		{name: "youtube start seconds", raw: "https://youtu.be/" + videoID + "?t=42", want: videoAt("42")},
+		{name: "youtube start with s", raw: "https://www.youtube.com/watch?v=" + videoID + "&t=42s", want: videoAt("42")},
+		{name: "youtube start duration", raw: "https://www.youtube.com/watch?v=" + videoID + "&t=1h2m3s", want: videoAt("3723")},
+		{name: "youtube embed start", raw: "https://www.youtube.com/embed/" + videoID + "?start=90", want: videoAt("90")},
+		{name: "youtube bad start", raw: "https://www.youtube.com/watch?v=" + videoID + "&t=soon", want: video},
+		{name: "youtube playlist", raw: "https://www.youtube.com/playlist?list=PL123", wantErr: true},
+		{name: "youtube short id", raw: "https://www.youtube.com/watch?v=abc", wantErr: true},
+		{name: "youtube channel", raw: "https://www.youtube.com/@school", wantErr: true, errIs: ErrUnsupported},
+
+		{name: "vimeo", raw: "https://vimeo.com/76979871", wantErr: true, errIs: ErrUnsupported},
+		{name: "vimeo player", raw: "https://player.vimeo.com/video/76979871", wantErr: true, errIs: ErrUnsupported},
+		{name: "lookalike host", raw: "https://drive.google.com.example.org/file/d/" + fileID + "/view", wantErr: true, errIs: ErrUnsupported},
+		{name: "not a url", raw: "", wantErr: true, errIs: ErrUnsupported},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Normalize(tc.raw)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Normalize = %+v, want an error", got)
+				}
+				if tc.errIs != nil && !errors.Is(err, tc.errIs) {
+					t.Errorf("Normalize error = %v, want %v", err, tc.errIs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("Normalize = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
@@ -31,26 +31,27 @@ func parseToken(tokenStr string) (int, error) {
 	return int(userIDFloat), nil
 }
 
-func AuthRequired() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var tokenStr string
+// tokenFromRequest reads the JWT from the auth cookie or the Authorization header.
+func tokenFromRequest(c *gin.Context) string {
+	// 1. Try httpOnly cookie first (browser flow)
+	if cookie, err := c.Cookie("eduhub_token"); err == nil && cookie != "" {
+		return cookie
+	}
 
-		// 1. Try httpOnly cookie first (browser flow)
-		if cookie, err := c.Cookie("eduhub_token"); err == nil && cookie != "" {
-			tokenStr = cookie
-		}
-
-		// 2. Fall back to Authorization header (API clients / tools)
-		if tokenStr == "" {
-			authHeader := c.GetHeader("Authorization")
-			if authHeader != "" {
-				parts := strings.SplitN(authHeader, " ", 2)
-				if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
-					tokenStr = parts[1]
-				}
-			}
+	// 2. Fall back to Authorization header (API clients / tools)
+	authHeader := c.GetHeader("Authorization")
+	if authHeader != "" {
+		parts := strings.SplitN(authHeader, " ", 2)
+		if len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+			return parts[1]
 		}
+	}
+	return ""
+}
 
+func AuthRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenStr := tokenFromRequest(c)
 		if tokenStr == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
 			c.Abort()
@@ -68,3 +69,16 @@ func AuthRequired() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OptionalAuth sets user_id when a valid token is present but lets anonymous
+// requests through, for endpoints that serve both guests and logged-in users.
+func OptionalAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if tokenStr := tokenFromRequest(c); tokenStr != "" {
+			if userID, err := parseToken(tokenStr); err == nil {
+				c.Set("user_id", userID)
+			}
+		}
+		c.Next()
+	}
+}
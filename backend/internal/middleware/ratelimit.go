@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"edu-web-backend/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit takes a token from the caller's bucket for the policy: per user
+// when a login was resolved earlier in the chain (OptionalAuth or
+// AuthRequired), otherwise per client IP, which only honours X-Forwarded-For
+// from TRUSTED_PROXIES. It sets the RateLimit-* headers on every response and
+// answers 429 once the bucket is empty.
+//
+// A policy with no burst disables the limit. If the store fails the request is
+// let through: losing abuse protection briefly is better than taking the chat
+// down with the database.
+func RateLimit(store ratelimit.Store, policy ratelimit.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if policy.Burst <= 0 {
+			c.Next()
+			return
+		}
+
+		key := policy.Name + ":ip:" + c.ClientIP()
+		if userID, ok := c.Get("user_id"); ok {
+			key = fmt.Sprintf("%s:user:%d", policy.Name, userID.(int))
+		}
+
+		res, err := store.Allow(c.Request.Context(), key, policy, time.Now())
+		if err != nil {
+			log.Printf("rate limit %s: %v", policy.Name, err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Policy", policy.String())
+		c.Header("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.Header("RateLimit-Reset", strconv.Itoa(seconds(res.Reset)))
+		if !res.Allowed {
+			retry := seconds(res.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retry))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":       "too many requests, please try again later",
+				"retry_after": retry,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// seconds rounds up so clients never retry a moment too early.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
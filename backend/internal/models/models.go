@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Video struct {
 	ID          int       `json:"id" db:"id"`
@@ -10,8 +13,14 @@ type Video struct {
 	EmbedURL    string    `json:"embed_url" db:"embed_url"`
 	Thumbnail   string    `json:"thumbnail" db:"thumbnail"`
 	Category    string    `json:"category" db:"category"`
+	Tags        []string  `json:"tags" db:"tags"`
 	Order       int       `json:"order" db:"order_num"`
+	MediaID     *int      `json:"media_id,omitempty" db:"media_id"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+
+	// DurationSeconds is the stored length; Duration formats it for display.
+	DurationSeconds *int   `json:"duration_seconds" db:"duration_seconds"`
+	Duration        string `json:"duration" db:"-"`
 }
 
 type Audio struct {
@@ -21,24 +30,124 @@ type Audio struct {
 	DriveURL    string    `json:"drive_url" db:"drive_url"`
 	EmbedURL    string    `json:"embed_url" db:"embed_url"`
 	Category    string    `json:"category" db:"category"`
-	Duration    string    `json:"duration" db:"duration"`
+	Tags        []string  `json:"tags" db:"tags"`
 	Order       int       `json:"order" db:"order_num"`
+	MediaID     *int      `json:"media_id,omitempty" db:"media_id"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+
+	// DurationSeconds is the stored length; Duration formats it for display.
+	DurationSeconds *int   `json:"duration_seconds" db:"duration_seconds"`
+	Duration        string `json:"duration" db:"-"`
+}
+
+// MediaFile is an audio or video file uploaded to the media library. Videos
+// and audios that play it link to it by MediaID.
+type MediaFile struct {
+	ID          int       `json:"id" db:"id"`
+	StorageKey  string    `json:"-" db:"storage_key"`
+	Filename    string    `json:"filename" db:"filename"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Kind        string    `json:"kind" db:"kind"` // "video" or "audio"
+	SizeBytes   int64     `json:"size_bytes" db:"size_bytes"`
+	SHA256      string    `json:"sha256" db:"sha256"`
+	UploadedBy  *int      `json:"uploaded_by" db:"uploaded_by"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	URL         string    `json:"url" db:"-"`
+
+	// Read from the container headers on upload; nil or empty when the
+	// format does not say.
+	DurationSeconds *int   `json:"duration_seconds" db:"duration_seconds"`
+	Duration        string `json:"duration" db:"-"`
+	Bitrate         *int   `json:"bitrate" db:"bitrate"` // bits per second
+	Codec           string `json:"codec" db:"codec"`
+	Width           *int   `json:"width" db:"width"`
+	Height          *int   `json:"height" db:"height"`
 }
 
+// ContentSuggestion is a video or audio Buddy recommends alongside a reply.
+type ContentSuggestion struct {
+	Type        string `json:"type"` // "video" or "audio"
+	ID          int    `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	EmbedURL    string `json:"embed_url"`
+	Thumbnail   string `json:"thumbnail,omitempty"`
+	Duration    string `json:"duration,omitempty"`
+	Category    string `json:"category"`
+}
+
+// QR code visibility. Private codes are left out of the public list and
+// their images are only served to the owner and admins; their short links
+// still work for whoever scans them.
+const (
+	QRPublic  = "public"
+	QRPrivate = "private"
+)
+
 type QRCode struct {
-	ID        int       `json:"id" db:"id"`
-	Label     string    `json:"label" db:"label"`
-	TargetURL string    `json:"target_url" db:"target_url"`
-	Type      string    `json:"type" db:"type"`
-	QRData    string    `json:"qr_data" db:"qr_data"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID        int    `json:"id" db:"id"`
+	Label     string `json:"label" db:"label"`
+	TargetURL string `json:"target_url" db:"target_url"`
+	Type      string `json:"type" db:"type"`
+	// Payload holds the fields of Wi-Fi, contact, event, geo and SMS codes,
+	// which encode their content directly instead of the short link.
+	Payload    json.RawMessage `json:"payload,omitempty" db:"payload"`
+	Slug       string          `json:"slug" db:"slug"`
+	ShortURL   string          `json:"short_url"`                  // public /q/:slug link the image encodes
+	CreatedBy  *int            `json:"created_by" db:"created_by"` // nil for codes made before ownership and by the CLI
+	Visibility string          `json:"visibility" db:"visibility"`
+	ExpiresAt  *time.Time      `json:"expires_at" db:"expires_at"`
+	Expired    bool            `json:"expired"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// QRLogo is an uploaded image that branded codes can place in their center.
+type QRLogo struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Data        []byte    `json:"-"`
+	UploadedBy  int       `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// QRScan is one visit to a code's short link. No IP address is kept.
+type QRScan struct {
+	QRCodeID  int
+	UserAgent string
+	Referer   string
+	Device    string // mobile, tablet, desktop, bot or other
+}
+
+type QRScanDay struct {
+	Day   time.Time `json:"day"`
+	Count int       `json:"count"`
+}
+
+type QRReferer struct {
+	Referer string `json:"referer"`
+	Count   int    `json:"count"`
+}
+
+type QRScanStats struct {
+	QRCodeID   int            `json:"qrcode_id"`
+	Total      int            `json:"total"`
+	LastScanAt *time.Time     `json:"last_scan_at"`
+	Daily      []QRScanDay    `json:"daily"`
+	Devices    map[string]int `json:"devices"`
+	Referers   []QRReferer    `json:"referers"`
 }
 
 type ChatSession struct {
-	ID        int       `json:"id" db:"id"`
-	SessionID string    `json:"session_id" db:"session_id"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	ID            int        `json:"id" db:"id"`
+	SessionID     string     `json:"session_id" db:"session_id"`
+	UserID        *int       `json:"user_id,omitempty" db:"user_id"`
+	AnonID        string     `json:"-" db:"anon_id"`
+	Lang          string     `json:"lang,omitempty" db:"lang"`
+	MessageCount  int        `json:"message_count" db:"-"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty" db:"-"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
 }
 
 type ChatMessage struct {
@@ -46,6 +155,7 @@ type ChatMessage struct {
 	SessionID string    `json:"session_id" db:"session_id"`
 	Role      string    `json:"role" db:"role"`
 	Content   string    `json:"content" db:"content"`
+	Crisis    bool      `json:"crisis,omitempty" db:"crisis"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -55,6 +165,7 @@ type PsychScenario struct {
 	Trigger  string `json:"trigger" db:"trigger"`
 	Response string `json:"response" db:"response"`
 	Tips     string `json:"tips" db:"tips"`
+	Lang     string `json:"lang" db:"lang"`
 }
 
 type User struct {
@@ -63,9 +174,20 @@ type User struct {
 	Email        string    `json:"email" db:"email"`
 	PasswordHash string    `json:"-" db:"password_hash"`
 	DisplayName  string    `json:"display_name" db:"display_name"`
+	Role         string    `json:"role" db:"role"`
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 }
 
+// User roles. Students are the default; teachers run attendance and add
+// videos, audios and media files but see nothing of counseling; counselors
+// and admins get staff views.
+const (
+	RoleStudent   = "student"
+	RoleTeacher   = "teacher"
+	RoleCounselor = "counselor"
+	RoleAdmin     = "admin"
+)
+
 type DirectMessage struct {
 	ID         int       `json:"id" db:"id"`
 	SenderID   int       `json:"sender_id" db:"sender_id"`
@@ -74,3 +196,224 @@ type DirectMessage struct {
 	IsRead     bool      `json:"is_read" db:"is_read"`
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`
 }
+
+type MoodEntry struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Score     int       `json:"score" db:"score"`
+	Tags      []string  `json:"tags" db:"tags"`
+	Note      string    `json:"note" db:"note"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type MoodTrendPoint struct {
+	PeriodStart time.Time      `json:"period_start"`
+	Average     float64        `json:"average"`
+	Count       int            `json:"count"`
+	TagCounts   map[string]int `json:"tag_counts"`
+}
+
+type DailyMood struct {
+	Day     time.Time `json:"day"`
+	Average float64   `json:"average"`
+}
+
+type ScreeningResult struct {
+	ID            int       `json:"id" db:"id"`
+	UserID        int       `json:"user_id" db:"user_id"`
+	Questionnaire string    `json:"questionnaire" db:"questionnaire"`
+	Answers       []int     `json:"answers" db:"answers"`
+	Total         int       `json:"total" db:"total"`
+	Severity      string    `json:"severity" db:"severity"`
+	Crisis        bool      `json:"crisis" db:"crisis"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// ScreeningSummary is one row of the counselor aggregate view: how many
+// submissions fell into a severity band, and how many students' latest result
+// is in that band.
+type ScreeningSummary struct {
+	Questionnaire string `json:"questionnaire"`
+	Severity      string `json:"severity"`
+	Submissions   int    `json:"submissions"`
+	Students      int    `json:"students"`
+	Crisis        int    `json:"crisis"`
+}
+
+// ExerciseState tracks a guided exercise within one chat session. Status is
+// "offered" until the student accepts, then "active" while Step advances.
+type ExerciseState struct {
+	SessionID  string    `json:"session_id" db:"session_id"`
+	Exercise   string    `json:"exercise" db:"exercise"`
+	Step       int       `json:"step" db:"step"`
+	TotalSteps int       `json:"total_steps" db:"-"`
+	Status     string    `json:"status" db:"status"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type SafetyContact struct {
+	Name     string `json:"name"`
+	Phone    string `json:"phone"`
+	Relation string `json:"relation,omitempty"`
+}
+
+// SafetyPlanContent follows the Stanley-Brown safety plan sections.
+type SafetyPlanContent struct {
+	WarningSigns         []string        `json:"warning_signs"`
+	CopingStrategies     []string        `json:"coping_strategies"`
+	Contacts             []SafetyContact `json:"contacts"`
+	ProfessionalContacts []SafetyContact `json:"professional_contacts"`
+	SafeEnvironment      []string        `json:"safe_environment"`
+}
+
+type SafetyPlan struct {
+	ID          int               `json:"id" db:"id"`
+	UserID      int               `json:"user_id" db:"user_id"`
+	CounselorID *int              `json:"counselor_id" db:"counselor_id"`
+	Version     int               `json:"version" db:"current_version"`
+	Content     SafetyPlanContent `json:"content" db:"content"`
+	EditedBy    int               `json:"edited_by" db:"edited_by"`
+	CreatedAt   time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+type SafetyPlanVersion struct {
+	Version   int               `json:"version" db:"version"`
+	Content   SafetyPlanContent `json:"content" db:"content"`
+	EditedBy  int               `json:"edited_by" db:"edited_by"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+}
+
+// Anonymous question states: open -> claimed -> answered -> closed. Open and
+// claimed questions can also be closed directly.
+const (
+	QuestionOpen     = "open"
+	QuestionClaimed  = "claimed"
+	QuestionAnswered = "answered"
+	QuestionClosed   = "closed"
+)
+
+// AnonymousQuestion is never linked to a user. The student reads the reply
+// with a secret token; only its hash is stored.
+type AnonymousQuestion struct {
+	ID         int        `json:"id" db:"id"`
+	Question   string     `json:"question" db:"question"`
+	Status     string     `json:"status" db:"status"`
+	Urgent     bool       `json:"urgent" db:"urgent"`
+	ClaimedBy  *int       `json:"claimed_by,omitempty" db:"claimed_by"`
+	Answer     string     `json:"answer" db:"answer"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	AnsweredAt *time.Time `json:"answered_at,omitempty" db:"answered_at"`
+}
+
+// Availability is a recurring weekly block split into SlotMinutes-long slots.
+// Weekday follows time.Weekday (0 = Sunday); times are "HH:MM" in school time.
+type Availability struct {
+	ID          int    `json:"id" db:"id"`
+	CounselorID int    `json:"counselor_id" db:"counselor_id"`
+	Weekday     int    `json:"weekday" db:"weekday"`
+	StartTime   string `json:"start_time" db:"start_time"`
+	EndTime     string `json:"end_time" db:"end_time"`
+	SlotMinutes int    `json:"slot_minutes" db:"slot_minutes"`
+}
+
+// AvailabilityException blocks a date, or part of it when StartTime/EndTime are set.
+type AvailabilityException struct {
+	ID          int    `json:"id" db:"id"`
+	CounselorID int    `json:"counselor_id" db:"counselor_id"`
+	Date        string `json:"date" db:"date"`
+	StartTime   string `json:"start_time" db:"start_time"`
+	EndTime     string `json:"end_time" db:"end_time"`
+	Reason      string `json:"reason" db:"reason"`
+}
+
+const (
+	AppointmentBooked    = "booked"
+	AppointmentCancelled = "cancelled"
+)
+
+type Appointment struct {
+	ID          int       `json:"id" db:"id"`
+	CounselorID int       `json:"counselor_id" db:"counselor_id"`
+	StudentID   int       `json:"student_id" db:"student_id"`
+	StartsAt    time.Time `json:"starts_at" db:"starts_at"`
+	EndsAt      time.Time `json:"ends_at" db:"ends_at"`
+	Status      string    `json:"status" db:"status"`
+	Note        string    `json:"note" db:"note"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type Slot struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+type ExerciseLogEntry struct {
+	Exercise  string    `json:"exercise" db:"exercise"`
+	Status    string    `json:"status" db:"status"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// CategorySpan is a run of consecutive student messages with the same detected category.
+type CategorySpan struct {
+	Category string    `json:"category"`
+	From     time.Time `json:"from"`
+	To       time.Time `json:"to"`
+	Messages int       `json:"messages"`
+}
+
+type SummaryStatement struct {
+	At       time.Time `json:"at"`
+	Text     string    `json:"text"`
+	Category string    `json:"category,omitempty"`
+	Crisis   bool      `json:"crisis,omitempty"`
+}
+
+// SessionSummary is the handoff summary of one Buddy session for a counselor.
+type SessionSummary struct {
+	SessionID     string             `json:"session_id"`
+	From          time.Time          `json:"from"`
+	To            time.Time          `json:"to"`
+	Messages      int                `json:"messages"`
+	Categories    []CategorySpan     `json:"categories"`
+	KeyStatements []SummaryStatement `json:"key_statements"`
+	Exercises     []ExerciseLogEntry `json:"exercises"`
+	CrisisFlags   []SummaryStatement `json:"crisis_flags"`
+}
+
+// ChatSummaryShare records a summary a student sent to a counselor and the
+// direct message that carries it.
+type ChatSummaryShare struct {
+	ID          int            `json:"id" db:"id"`
+	SessionID   string         `json:"session_id" db:"session_id"`
+	StudentID   int            `json:"student_id" db:"student_id"`
+	CounselorID int            `json:"counselor_id" db:"counselor_id"`
+	MessageID   int            `json:"message_id" db:"message_id"`
+	Summary     SessionSummary `json:"summary" db:"summary"`
+	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
+}
+
+// AttendanceSession is one class check-in run by a teacher. While it is open
+// the teacher shows a QR code that changes every RotateSeconds.
+type AttendanceSession struct {
+	ID            int       `json:"id" db:"id"`
+	TeacherID     int       `json:"teacher_id" db:"teacher_id"`
+	Title         string    `json:"title" db:"title"`
+	RotateSeconds int       `json:"rotate_seconds" db:"rotate_seconds"`
+	StartedAt     time.Time `json:"started_at" db:"started_at"`
+	EndsAt        time.Time `json:"ends_at" db:"ends_at"`
+	CheckIns      int       `json:"check_ins" db:"check_ins"`
+}
+
+// Open reports whether students can still check in at now.
+func (s *AttendanceSession) Open(now time.Time) bool {
+	return now.Before(s.EndsAt)
+}
+
+type AttendanceCheckIn struct {
+	SessionID   int       `json:"session_id" db:"session_id"`
+	UserID      int       `json:"user_id" db:"user_id"`
+	Username    string    `json:"username" db:"username"`
+	DisplayName string    `json:"display_name" db:"display_name"`
+	CheckedInAt time.Time `json:"checked_in_at" db:"checked_in_at"`
+}
@@ -0,0 +1,43 @@
+// Package notify delivers messages to users outside of a request, such as
+// appointment confirmations and reminders.
+package notify
+
+import (
+	"context"
+	"edu-web-backend/internal/repository"
+	"log"
+)
+
+// Message is a notification from one user (usually the counselor) to another.
+type Message struct {
+	FromUserID int
+	ToUserID   int
+	Body       string
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, m Message) error
+}
+
+// DirectMessageNotifier posts notifications into the direct message thread
+// between the two users, so the conversation is already open when they reply.
+type DirectMessageNotifier struct {
+	db *repository.DB
+}
+
+func NewDirectMessageNotifier(db *repository.DB) *DirectMessageNotifier {
+	return &DirectMessageNotifier{db: db}
+}
+
+func (n *DirectMessageNotifier) Notify(ctx context.Context, m Message) error {
+	_, err := n.db.SaveMessage(ctx, m.FromUserID, m.ToUserID, m.Body)
+	return err
+}
+
+// LogNotifier only logs notifications. Useful in development.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, m Message) error {
+	log.Printf("notify %d -> %d: %s", m.FromUserID, m.ToUserID, m.Body)
+	return nil
+}
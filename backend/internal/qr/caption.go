@@ -0,0 +1,76 @@
+package qr
+
+import (
+	_ "embed"
+	"image"
+	"image/color"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// captionTTF is DejaVu Sans (see fonts/LICENSE), which unlike the Go fonts
+// has the precomposed Vietnamese letters. PDFs embed the glyphs they use.
+//
+//go:embed fonts/DejaVuSans.ttf
+var captionTTF []byte
+
+var (
+	captionFontOnce sync.Once
+	captionFont     *opentype.Font
+)
+
+func loadCaptionFont() *opentype.Font {
+	captionFontOnce.Do(func() {
+		f, err := opentype.Parse(captionTTF)
+		if err != nil {
+			panic("qr: embedded caption font: " + err.Error())
+		}
+		captionFont = f
+	})
+	return captionFont
+}
+
+// captionLayout picks a font size that fits text within width and returns
+// it with the text's advance at that size. Vector formats use the same
+// metrics so captions line up the same way everywhere.
+func captionLayout(text string, width, height int) (size, advance float64) {
+	size = float64(height) * 0.5
+	advance = measureCaption(text, size)
+	if limit := float64(width) * 0.92; advance > limit {
+		size *= limit / advance
+		advance = limit
+	}
+	return size, advance
+}
+
+func measureCaption(text string, size float64) float64 {
+	face, err := opentype.NewFace(loadCaptionFont(), &opentype.FaceOptions{Size: size, DPI: 72})
+	if err != nil {
+		return 0
+	}
+	defer face.Close()
+	return float64(font.MeasureString(face, text)) / 64
+}
+
+// drawCaption writes text centered in area.
+func drawCaption(dst *image.RGBA, text string, c color.RGBA, area image.Rectangle) {
+	size, advance := captionLayout(text, area.Dx(), area.Dy())
+	face, err := opentype.NewFace(loadCaptionFont(), &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return
+	}
+	defer face.Close()
+	d := font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot: fixed.Point26_6{
+			X: fixed.Int26_6((float64(area.Min.X) + (float64(area.Dx())-advance)/2) * 64),
+			Y: fixed.Int26_6((float64(area.Min.Y) + float64(area.Dy())*0.65) * 64),
+		},
+	}
+	d.DrawString(text)
+}
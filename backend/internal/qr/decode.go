@@ -0,0 +1,77 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	_ "golang.org/x/image/webp" // chat apps often save screenshots as WebP
+)
+
+const (
+	// MaxDecodeBytes bounds uploaded photos and screenshots.
+	MaxDecodeBytes = 8 << 20
+	// maxDecodePixels bounds the decoded bitmap: 64 MB as RGBA, and a
+	// full-size pass that takes about a second.
+	maxDecodePixels = 4096 * 4096
+	// decodeSide is the longest side a photo is scaled down to for the first
+	// attempt; the detector copes better with codes that are not tiny
+	// details of a huge image, and it is much faster.
+	decodeSide = 1200
+)
+
+// ErrNoCode means the image was readable but no QR code was found in it.
+var ErrNoCode = errors.New("no QR code found in the image")
+
+// DecodeImage reads a PNG, JPEG, GIF or WebP photo or screenshot and returns
+// the text of the QR code in it.
+func DecodeImage(data []byte) (string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("image must be a PNG, JPEG, GIF or WebP file")
+	}
+	if cfg.Width == 0 || cfg.Height == 0 || cfg.Width*cfg.Height > maxDecodePixels {
+		return "", fmt.Errorf("image dimensions are out of range")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("image could not be decoded: %w", err)
+	}
+	return Decode(img)
+}
+
+// Decode finds a QR code in img. Large images are tried scaled down first
+// and at full size only when that fails, each also with inverted colors for
+// light-on-dark codes.
+func Decode(img image.Image) (string, error) {
+	candidates := []image.Image{img}
+	if b := img.Bounds(); max(b.Dx(), b.Dy()) > decodeSide {
+		w, h := b.Dx()*decodeSide/max(b.Dx(), b.Dy()), b.Dy()*decodeSide/max(b.Dx(), b.Dy())
+		candidates = []image.Image{scaleImage(img, max(w, 1), max(h, 1)), img}
+	}
+
+	hints := map[gozxing.DecodeHintType]interface{}{
+		gozxing.DecodeHintType_TRY_HARDER: true,
+		// Codes from this server carry UTF-8 without an ECI marker; without
+		// the hint Vietnamese text may be guessed as another charset.
+		gozxing.DecodeHintType_CHARACTER_SET: "UTF-8",
+	}
+	reader := qrcode.NewQRCodeReader()
+	for _, c := range candidates {
+		src := gozxing.NewLuminanceSourceFromImage(c)
+		for _, s := range []gozxing.LuminanceSource{src, gozxing.NewInvertedLuminanceSource(src)} {
+			bmp, err := gozxing.NewBinaryBitmap(gozxing.NewHybridBinarizer(s))
+			if err != nil {
+				continue
+			}
+			if res, err := reader.Decode(bmp, hints); err == nil {
+				return res.GetText(), nil
+			}
+			reader.Reset()
+		}
+	}
+	return "", ErrNoCode
+}
@@ -0,0 +1,215 @@
+package qr
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"image"
+	"image/draw"
+	"image/png"
+	"strings"
+)
+
+// drawing is a matrix ready to draw with the options it was encoded for.
+type drawing struct {
+	m    Matrix
+	o    Options
+	n    int
+	logo *box // where the logo goes, in module units; nil without one
+}
+
+func newDrawing(m Matrix, o Options) *drawing {
+	d := &drawing{m: m, o: o, n: len(m)}
+	if o.Logo != nil {
+		b := logoBox(d.n, o.Margin, o.Logo)
+		d.logo = &b
+		m.clear(b)
+	}
+	return d
+}
+
+func (d *drawing) dark(x, y int) bool {
+	return x >= 0 && y >= 0 && x < d.n && y < d.n && d.m[y][x]
+}
+
+// corners reports which corners of the dark module at (x, y) are rounded:
+// those where neither neighbour along the two edges is dark, so runs of
+// modules stay joined and only outer corners curve.
+type corners struct {
+	tl, tr, br, bl bool
+}
+
+func (d *drawing) corners(x, y int) corners {
+	if !d.o.Rounded {
+		return corners{}
+	}
+	up, down, left, right := d.dark(x, y-1), d.dark(x, y+1), d.dark(x-1, y), d.dark(x+1, y)
+	return corners{
+		tl: !up && !left,
+		tr: !up && !right,
+		br: !down && !right,
+		bl: !down && !left,
+	}
+}
+
+// runs calls fn for every horizontal run of dark modules, which keeps square
+// SVG and PDF output to one shape per run instead of one per module.
+func (m Matrix) runs(fn func(x, y, length int)) {
+	for y, row := range m {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fn(start, y, x-start)
+		}
+	}
+}
+
+// SVG draws the code in pixel units with modules scaled from module units.
+func (d *drawing) SVG() ([]byte, error) {
+	size, height := d.o.Size, d.o.Size+d.o.CaptionHeight()
+	scale := float64(size) / float64(d.n)
+	fg, bg := Hex(d.o.Foreground), Hex(d.o.Background)
+
+	var path strings.Builder
+	if d.o.Rounded {
+		for y := range d.m {
+			for x := range d.m[y] {
+				if d.m[y][x] {
+					svgModule(&path, x, y, d.corners(x, y))
+				}
+			}
+		}
+	} else {
+		d.m.runs(func(x, y, length int) {
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", x, y, length, length)
+		})
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, size, height, size, height)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="%s"/>`, size, height, bg)
+	rendering := ` shape-rendering="crispEdges"`
+	if d.o.Rounded {
+		rendering = ""
+	}
+	fmt.Fprintf(&b, `<path transform="scale(%s)" fill="%s"%s d="%s"/>`, fnum(scale), fg, rendering, path.String())
+
+	if d.logo != nil {
+		data, err := logoPNG(d.o.Logo, logoSide)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, `<image x="%s" y="%s" width="%s" height="%s" href="data:image/png;base64,%s"/>`,
+			fnum(d.logo.x*scale), fnum(d.logo.y*scale), fnum(d.logo.w*scale), fnum(d.logo.h*scale),
+			base64.StdEncoding.EncodeToString(data))
+	}
+
+	if d.o.Caption != "" {
+		capHeight := d.o.CaptionHeight()
+		fontSize, _ := captionLayout(d.o.Caption, size, capHeight)
+		fmt.Fprintf(&b, `<text x="%s" y="%s" font-family="DejaVu Sans, Verdana, sans-serif" font-size="%s" text-anchor="middle" fill="%s">%s</text>`,
+			fnum(float64(size)/2), fnum(float64(size)+float64(capHeight)*0.65), fnum(fontSize), fg, html.EscapeString(d.o.Caption))
+	}
+	b.WriteString("</svg>\n")
+	return b.Bytes(), nil
+}
+
+// svgModule outlines one module, replacing rounded corners with arcs.
+func svgModule(b *strings.Builder, x, y int, c corners) {
+	r := func(rounded bool) float64 {
+		if rounded {
+			return 0.5
+		}
+		return 0
+	}
+	fx, fy := float64(x), float64(y)
+	tl, tr, br, bl := r(c.tl), r(c.tr), r(c.br), r(c.bl)
+	fmt.Fprintf(b, "M%s %sH%s", fnum(fx+tl), fnum(fy), fnum(fx+1-tr))
+	if c.tr {
+		fmt.Fprintf(b, "A.5 .5 0 0 1 %s %s", fnum(fx+1), fnum(fy+tr))
+	}
+	fmt.Fprintf(b, "V%s", fnum(fy+1-br))
+	if c.br {
+		fmt.Fprintf(b, "A.5 .5 0 0 1 %s %s", fnum(fx+1-br), fnum(fy+1))
+	}
+	fmt.Fprintf(b, "H%s", fnum(fx+bl))
+	if c.bl {
+		fmt.Fprintf(b, "A.5 .5 0 0 1 %s %s", fnum(fx), fnum(fy+1-bl))
+	}
+	fmt.Fprintf(b, "V%s", fnum(fy+tl))
+	if c.tl {
+		fmt.Fprintf(b, "A.5 .5 0 0 1 %s %s", fnum(fx+tl), fnum(fy))
+	}
+	b.WriteString("Z")
+}
+
+// PNG draws whole pixels per module so scanners never see blurred edges.
+// The image is Size pixels wide; rounding leftovers are added to the quiet
+// zone. Codes with more modules than Size get one pixel per module.
+func (d *drawing) PNG() ([]byte, error) {
+	size := d.o.Size
+	scale := size / d.n
+	if scale < 1 {
+		scale, size = 1, d.n
+	}
+	offset := (size - d.n*scale) / 2
+	capHeight := d.o.CaptionHeight()
+
+	img := image.NewRGBA(image.Rect(0, 0, size, size+capHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(d.o.Background), image.Point{}, draw.Src)
+	fg := image.NewUniform(d.o.Foreground)
+	half := float64(scale) / 2
+
+	for y, row := range d.m {
+		for x, dark := range row {
+			if !dark {
+				continue
+			}
+			px, py := offset+x*scale, offset+y*scale
+			c := d.corners(x, y)
+			if c == (corners{}) {
+				draw.Draw(img, image.Rect(px, py, px+scale, py+scale), fg, image.Point{}, draw.Src)
+				continue
+			}
+			// Skip pixels outside the quarter circle of a rounded corner.
+			for j := 0; j < scale; j++ {
+				for i := 0; i < scale; i++ {
+					u, v := float64(i)+0.5-half, float64(j)+0.5-half
+					rounded := (u < 0 && v < 0 && c.tl) || (u >= 0 && v < 0 && c.tr) ||
+						(u >= 0 && v >= 0 && c.br) || (u < 0 && v >= 0 && c.bl)
+					if rounded && u*u+v*v > half*half {
+						continue
+					}
+					img.SetRGBA(px+i, py+j, d.o.Foreground)
+				}
+			}
+		}
+	}
+
+	if d.logo != nil {
+		s := float64(scale)
+		rect := image.Rect(
+			offset+int(d.logo.x*s), offset+int(d.logo.y*s),
+			offset+int((d.logo.x+d.logo.w)*s), offset+int((d.logo.y+d.logo.h)*s),
+		)
+		logo := scaleImage(d.o.Logo, rect.Dx(), rect.Dy())
+		draw.Draw(img, rect, logo, image.Point{}, draw.Over)
+	}
+	if d.o.Caption != "" {
+		drawCaption(img, d.o.Caption, d.o.Foreground, image.Rect(0, size, size, size+capHeight))
+	}
+
+	var b bytes.Buffer
+	if err := png.Encode(&b, img); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
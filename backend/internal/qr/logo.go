@@ -0,0 +1,119 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // logos may be uploaded as GIF, JPEG or PNG
+	_ "image/jpeg"
+	"image/png"
+	"math"
+
+	xdraw "golang.org/x/image/draw"
+)
+
+const (
+	// MaxLogoBytes bounds uploaded logo files.
+	MaxLogoBytes = 1 << 20
+	// maxLogoPixels bounds the decoded size so a small file cannot expand
+	// into a huge bitmap.
+	maxLogoPixels = 1024 * 1024
+	// logoSide is the longest side a logo is kept at. The largest PNG draws
+	// it about 450 pixels wide, and SVG and PDF embed it at most this size.
+	logoSide = 512
+
+	// logoFraction is the logo's width relative to the symbol. At high
+	// error correction about 30% of the code may be unreadable; a logo
+	// this wide hides well under that.
+	logoFraction = 0.22
+)
+
+// DecodeLogo reads a PNG, JPEG or GIF logo and returns the image and its
+// format name.
+func DecodeLogo(data []byte) (image.Image, string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("logo must be a PNG, JPEG or GIF image")
+	}
+	if cfg.Width == 0 || cfg.Height == 0 || cfg.Width*cfg.Height > maxLogoPixels {
+		return nil, "", fmt.Errorf("logo dimensions are out of range")
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("logo could not be decoded: %w", err)
+	}
+	return img, format, nil
+}
+
+// PrepareLogo decodes an uploaded logo and shrinks it to at most logoSide
+// pixels, once, so stored logos are cheap to draw. It returns the image and
+// its PNG encoding for storage.
+func PrepareLogo(data []byte) (image.Image, []byte, error) {
+	img, _, err := DecodeLogo(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	img = fitLogo(img, logoSide)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, nil, err
+	}
+	return img, buf.Bytes(), nil
+}
+
+// box is a rectangle in module units.
+type box struct {
+	x, y, w, h float64
+}
+
+// logoBox centers the logo on the symbol, keeping its aspect ratio.
+func logoBox(n, margin int, logo image.Image) box {
+	side := float64(n-2*margin) * logoFraction
+	b := logo.Bounds()
+	w, h := side, side
+	if b.Dx() > b.Dy() {
+		h = side * float64(b.Dy()) / float64(b.Dx())
+	} else {
+		w = side * float64(b.Dx()) / float64(b.Dy())
+	}
+	c := float64(n) / 2
+	return box{c - w/2, c - h/2, w, h}
+}
+
+// clear turns off every module within half a module of the box, so the
+// logo sits on a clean background.
+func (m Matrix) clear(b box) {
+	x0, y0 := int(math.Floor(b.x-0.5)), int(math.Floor(b.y-0.5))
+	x1, y1 := int(math.Ceil(b.x+b.w+0.5)), int(math.Ceil(b.y+b.h+0.5))
+	for y := max(y0, 0); y < min(y1, len(m)); y++ {
+		for x := max(x0, 0); x < min(x1, len(m)); x++ {
+			m[y][x] = false
+		}
+	}
+}
+
+// scaleImage resizes img to w x h with a high-quality filter.
+func scaleImage(img image.Image, w, h int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, max(w, 1), max(h, 1)))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), xdraw.Over, nil)
+	return dst
+}
+
+// fitLogo scales img down so neither side exceeds limit pixels.
+func fitLogo(img image.Image, limit int) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= limit && b.Dy() <= limit {
+		return img
+	}
+	scale := float64(limit) / float64(max(b.Dx(), b.Dy()))
+	return scaleImage(img, int(float64(b.Dx())*scale), int(float64(b.Dy())*scale))
+}
+
+// logoPNG re-encodes the logo at most limit pixels wide for embedding.
+func logoPNG(img image.Image, limit int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, fitLogo(img, limit)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
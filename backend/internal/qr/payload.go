@@ -0,0 +1,332 @@
+package qr
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Payload types. Codes of any other type encode their short link.
+const (
+	PayloadWiFi   = "wifi"
+	PayloadVCard  = "vcard"
+	PayloadMeCard = "mecard"
+	PayloadEvent  = "event"
+	PayloadGeo    = "geo"
+	PayloadSMS    = "sms"
+)
+
+// Payload is structured content encoded directly into the code, for things
+// phones act on without opening a link.
+type Payload interface {
+	Validate() error
+	// Text is the string the code encodes.
+	Text() string
+}
+
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 .-]{2,19}$`)
+
+// MaxTargetURLLen bounds the destination of link codes.
+const MaxTargetURLLen = 2000
+
+// ValidateTargetURL only allows absolute web links, so a code's short link
+// cannot be used to redirect to other schemes.
+func ValidateTargetURL(raw string) error {
+	if len(raw) > MaxTargetURLLen {
+		return fmt.Errorf("target_url must be at most %d characters", MaxTargetURLLen)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("target_url must be an http or https URL")
+	}
+	return nil
+}
+
+// IsPayloadType reports whether codes of type t carry a structured payload.
+func IsPayloadType(t string) bool {
+	_, ok := payloadFactories[t]
+	return ok
+}
+
+var payloadFactories = map[string]func() Payload{
+	PayloadWiFi:   func() Payload { return &WiFi{} },
+	PayloadVCard:  func() Payload { return &Contact{} },
+	PayloadMeCard: func() Payload { return &Contact{mecard: true} },
+	PayloadEvent:  func() Payload { return &Event{} },
+	PayloadGeo:    func() Payload { return &Geo{} },
+	PayloadSMS:    func() Payload { return &SMS{} },
+}
+
+// ParsePayload decodes and validates the JSON fields of a payload type.
+// Unknown fields are rejected so typos do not silently drop data.
+func ParsePayload(kind string, raw []byte) (Payload, error) {
+	factory, ok := payloadFactories[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown payload type %q", kind)
+	}
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return nil, fmt.Errorf("payload is required for type %s", kind)
+	}
+	p := factory()
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(p); err != nil {
+		return nil, fmt.Errorf("invalid %s payload: %w", kind, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WiFi joins a network, in the WIFI: format Android and iOS cameras read.
+type WiFi struct {
+	SSID     string `json:"ssid"`
+	Password string `json:"password"`
+	Security string `json:"security"`
+	Hidden   bool   `json:"hidden"`
+}
+
+func (w *WiFi) Validate() error {
+	w.Security = strings.ToUpper(strings.TrimSpace(w.Security))
+	if w.Security == "" {
+		w.Security = "WPA"
+	}
+	if w.SSID == "" || len(w.SSID) > 32 {
+		return fmt.Errorf("ssid must be 1-32 bytes")
+	}
+	switch w.Security {
+	case "WPA":
+		if len(w.Password) < 8 || len(w.Password) > 63 {
+			return fmt.Errorf("WPA password must be 8-63 characters")
+		}
+	case "WEP":
+		switch n := len(w.Password); {
+		case n == 10 || n == 26:
+			if _, err := hex.DecodeString(w.Password); err != nil {
+				return fmt.Errorf("a WEP key of 10 or 26 characters must be hex digits")
+			}
+		case n != 5 && n != 13:
+			return fmt.Errorf("WEP password must be 5 or 13 characters, or 10 or 26 hex digits")
+		}
+	case "NOPASS":
+		w.Security = "nopass"
+		if w.Password != "" {
+			return fmt.Errorf("an open network has no password")
+		}
+	default:
+		return fmt.Errorf("security must be WPA, WEP or nopass")
+	}
+	return nil
+}
+
+func (w *WiFi) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "WIFI:T:%s;S:%s;", w.Security, escapeMeCard(w.SSID))
+	if w.Password != "" {
+		fmt.Fprintf(&b, "P:%s;", escapeMeCard(w.Password))
+	}
+	if w.Hidden {
+		b.WriteString("H:true;")
+	}
+	b.WriteString(";")
+	return b.String()
+}
+
+// Contact is a business card, encoded as vCard 3.0 or the shorter MECARD.
+type Contact struct {
+	Name    string `json:"name"`
+	Phone   string `json:"phone,omitempty"`
+	Email   string `json:"email,omitempty"`
+	Org     string `json:"org,omitempty"`
+	Title   string `json:"title,omitempty"`
+	URL     string `json:"url,omitempty"`
+	Address string `json:"address,omitempty"`
+	Note    string `json:"note,omitempty"`
+
+	mecard bool
+}
+
+func (ct *Contact) Validate() error {
+	for _, f := range []*string{&ct.Name, &ct.Phone, &ct.Email, &ct.Org, &ct.Title, &ct.URL, &ct.Address, &ct.Note} {
+		*f = strings.TrimSpace(*f)
+	}
+	if ct.Name == "" || len(ct.Name) > 100 {
+		return fmt.Errorf("name must be 1-100 characters")
+	}
+	if ct.Phone == "" && ct.Email == "" {
+		return fmt.Errorf("a contact needs a phone or an email")
+	}
+	if ct.Phone != "" && !phonePattern.MatchString(ct.Phone) {
+		return fmt.Errorf("phone is not a valid phone number")
+	}
+	if ct.Email != "" && (!strings.Contains(ct.Email, "@") || len(ct.Email) > 254) {
+		return fmt.Errorf("email is not a valid address")
+	}
+	if len(ct.Org) > 100 || len(ct.Title) > 100 || len(ct.URL) > 500 || len(ct.Address) > 300 || len(ct.Note) > 500 {
+		return fmt.Errorf("contact field is too long")
+	}
+	return nil
+}
+
+func (ct *Contact) Text() string {
+	if ct.mecard {
+		return ct.meCard()
+	}
+	lines := []string{"BEGIN:VCARD", "VERSION:3.0",
+		"N:" + escapeText(ct.Name) + ";;;;",
+		"FN:" + escapeText(ct.Name),
+	}
+	add := func(prop, value string) {
+		if value != "" {
+			lines = append(lines, prop+":"+escapeText(value))
+		}
+	}
+	add("ORG", ct.Org)
+	add("TITLE", ct.Title)
+	add("TEL", ct.Phone)
+	add("EMAIL", ct.Email)
+	add("URL", ct.URL)
+	if ct.Address != "" {
+		lines = append(lines, "ADR:;;"+escapeText(ct.Address)+";;;;")
+	}
+	add("NOTE", ct.Note)
+	lines = append(lines, "END:VCARD")
+	return strings.Join(lines, "\r\n")
+}
+
+func (ct *Contact) meCard() string {
+	var b strings.Builder
+	b.WriteString("MECARD:N:" + escapeMeCard(ct.Name) + ";")
+	for _, f := range []struct{ key, value string }{
+		{"ORG", ct.Org}, {"TEL", ct.Phone}, {"EMAIL", ct.Email},
+		{"URL", ct.URL}, {"ADR", ct.Address}, {"NOTE", ct.Note},
+	} {
+		if f.value != "" {
+			b.WriteString(f.key + ":" + escapeMeCard(f.value) + ";")
+		}
+	}
+	b.WriteString(";")
+	return b.String()
+}
+
+// Event adds a calendar entry, such as a parent meeting.
+type Event struct {
+	Summary     string    `json:"summary"`
+	Location    string    `json:"location,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+}
+
+func (e *Event) Validate() error {
+	e.Summary = strings.TrimSpace(e.Summary)
+	e.Location = strings.TrimSpace(e.Location)
+	e.Description = strings.TrimSpace(e.Description)
+	if e.Summary == "" || len(e.Summary) > 200 {
+		return fmt.Errorf("summary must be 1-200 characters")
+	}
+	if len(e.Location) > 200 || len(e.Description) > 500 {
+		return fmt.Errorf("event field is too long")
+	}
+	if e.Start.IsZero() || e.End.IsZero() {
+		return fmt.Errorf("start and end are required (RFC 3339)")
+	}
+	if !e.End.After(e.Start) {
+		return fmt.Errorf("end must be after start")
+	}
+	return nil
+}
+
+// Text returns the VEVENT. RFC 5545 requires UID and DTSTAMP; both are
+// derived from the event itself rather than the time of rendering, so the
+// same event always encodes the same text and decoding can find its code.
+func (e *Event) Text() string {
+	start := e.Start.UTC().Format("20060102T150405Z")
+	end := e.End.UTC().Format("20060102T150405Z")
+	uid := sha256.Sum256([]byte(strings.Join([]string{e.Summary, start, end, e.Location, e.Description}, "\x00")))
+	lines := []string{"BEGIN:VEVENT",
+		"UID:" + hex.EncodeToString(uid[:16]) + "@qr",
+		"DTSTAMP:" + start,
+		"SUMMARY:" + escapeText(e.Summary),
+		"DTSTART:" + start,
+		"DTEND:" + end,
+	}
+	if e.Location != "" {
+		lines = append(lines, "LOCATION:"+escapeText(e.Location))
+	}
+	if e.Description != "" {
+		lines = append(lines, "DESCRIPTION:"+escapeText(e.Description))
+	}
+	lines = append(lines, "END:VEVENT")
+	return strings.Join(lines, "\r\n")
+}
+
+// Geo opens a map at a point, optionally labelled with a search query.
+type Geo struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Query     string  `json:"query,omitempty"`
+}
+
+func (g *Geo) Validate() error {
+	g.Query = strings.TrimSpace(g.Query)
+	if g.Latitude < -90 || g.Latitude > 90 {
+		return fmt.Errorf("latitude must be between -90 and 90")
+	}
+	if g.Longitude < -180 || g.Longitude > 180 {
+		return fmt.Errorf("longitude must be between -180 and 180")
+	}
+	if len(g.Query) > 200 {
+		return fmt.Errorf("query must be at most 200 characters")
+	}
+	return nil
+}
+
+func (g *Geo) Text() string {
+	s := "geo:" + strconv.FormatFloat(g.Latitude, 'f', -1, 64) + "," + strconv.FormatFloat(g.Longitude, 'f', -1, 64)
+	if g.Query != "" {
+		s += "?q=" + url.QueryEscape(g.Query)
+	}
+	return s
+}
+
+// SMS opens a prefilled text message.
+type SMS struct {
+	Phone   string `json:"phone"`
+	Message string `json:"message,omitempty"`
+}
+
+func (s *SMS) Validate() error {
+	s.Phone = strings.TrimSpace(s.Phone)
+	if !phonePattern.MatchString(s.Phone) {
+		return fmt.Errorf("phone is not a valid phone number")
+	}
+	if len(s.Message) > 300 {
+		return fmt.Errorf("message must be at most 300 characters")
+	}
+	return nil
+}
+
+func (s *SMS) Text() string {
+	return "SMSTO:" + s.Phone + ":" + s.Message
+}
+
+// escapeMeCard escapes the separators of the MECARD and WIFI formats. They
+// have no escape for line breaks, which end the value in some readers, so
+// those become spaces.
+func escapeMeCard(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `:`, `\:`, `"`, `\"`, "\r\n", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+// escapeText escapes vCard and iCalendar text values.
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
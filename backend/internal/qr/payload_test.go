@@ -0,0 +1,84 @@
+package qr
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		raw     string
+		text    string
+		wantErr bool
+	}{
+		{name: "wep ascii", kind: PayloadWiFi, raw: `{"ssid":"Lab","password":"ab;cd","security":"wep"}`,
+			text: `WIFI:T:WEP;S:Lab;P:ab\;cd;;`},
+		{name: "wep hex", kind: PayloadWiFi, raw: `{"ssid":"Lab","password":"0123456789ABCDEF0123456789","security":"WEP"}`,
+			text: `WIFI:T:WEP;S:Lab;P:0123456789ABCDEF0123456789;;`},
+		{name: "wep not hex", kind: PayloadWiFi, raw: `{"ssid":"Lab","password":"password12","security":"WEP"}`, wantErr: true},
+		{name: "wep wrong length", kind: PayloadWiFi, raw: `{"ssid":"Lab","password":"abcdef","security":"WEP"}`, wantErr: true},
+		{name: "wifi line break", kind: PayloadWiFi, raw: `{"ssid":"Phong\nhoc","password":"12345678"}`,
+			text: `WIFI:T:WPA;S:Phong hoc;P:12345678;;`},
+		{name: "mecard line break", kind: PayloadMeCard, raw: `{"name":"Co Lan","phone":"0912345678","address":"12 Le Loi,\r\nQuan 1"}`,
+			text: `MECARD:N:Co Lan;TEL:0912345678;ADR:12 Le Loi\, Quan 1;;`},
+		{name: "event", kind: PayloadEvent, raw: `{"summary":"Hop phu huynh","location":"Phong 12","start":"2024-09-05T19:00:00+07:00","end":"2024-09-05T21:00:00+07:00"}`,
+			text: "BEGIN:VEVENT\r\nUID:@qr\r\nDTSTAMP:20240905T120000Z\r\nSUMMARY:Hop phu huynh\r\n" +
+				"DTSTART:20240905T120000Z\r\nDTEND:20240905T140000Z\r\nLOCATION:Phong 12\r\nEND:VEVENT"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := ParsePayload(tc.kind, []byte(tc.raw))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("ParsePayload = %q, want an error", p.Text())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := maskUID(p.Text()); got != tc.text {
+				t.Errorf("Text = %q, want %q", got, tc.text)
+			}
+		})
+	}
+}
+
+// The UID is a hash of the event, so it is stable but differs between events.
+func TestEventUID(t *testing.T) {
+	parse := func(raw string) string {
+		p, err := ParsePayload(PayloadEvent, []byte(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p.Text()
+	}
+	a := parse(`{"summary":"Hop","start":"2024-09-05T19:00:00+07:00","end":"2024-09-05T21:00:00+07:00"}`)
+	b := parse(`{"summary":"Hop","start":"2024-09-05T12:00:00Z","end":"2024-09-05T14:00:00Z"}`)
+	c := parse(`{"summary":"Hop","start":"2024-09-06T12:00:00Z","end":"2024-09-06T14:00:00Z"}`)
+	if a != b {
+		t.Errorf("the same event encodes differently:\n%s\n%s", a, b)
+	}
+	if uid(a) == uid(c) {
+		t.Errorf("different events share UID %s", uid(a))
+	}
+}
+
+func uid(text string) string {
+	for _, line := range strings.Split(text, "\r\n") {
+		if v, ok := strings.CutPrefix(line, "UID:"); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// maskUID drops the hash part of the UID so expectations stay readable.
+func maskUID(text string) string {
+	if id := uid(text); id != "" {
+		text = strings.Replace(text, "UID:"+id, "UID:"+id[strings.Index(id, "@"):], 1)
+	}
+	return text
+}
@@ -0,0 +1,195 @@
+package qr
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+)
+
+// bezierK places cubic control points to approximate a quarter circle.
+const bezierK = 0.5523
+
+// PDF draws the code as vector shapes on a single page Size points wide, so
+// it prints sharply at any scale.
+func (d *drawing) PDF() ([]byte, error) {
+	size, capHeight := d.o.Size, d.o.CaptionHeight()
+	height := size + capHeight
+	scale := float64(size) / float64(d.n)
+
+	var content bytes.Buffer
+	fmt.Fprintf(&content, "%s rg 0 0 %d %d re f\n", pdfColor(d.o.Background), size, height)
+	// The code sits at the top of the page, above the caption strip.
+	d.pdfCode(&content, 0, float64(height), scale)
+
+	var images []string
+	var resources string
+	if d.logo != nil {
+		// Images are drawn into the unit square with y up, so this
+		// transform is in unflipped page coordinates.
+		fmt.Fprintf(&content, "q %s 0 0 %s %s %s cm /Im1 Do Q\n",
+			fnum(d.logo.w*scale), fnum(d.logo.h*scale),
+			fnum(d.logo.x*scale), fnum(float64(height)-(d.logo.y+d.logo.h)*scale))
+		var err error
+		if images, err = pdfImage(d.o.Logo, 6); err != nil {
+			return nil, err
+		}
+		resources = " /XObject << /Im1 5 0 R >>"
+	}
+
+	// Objects: 1 catalog, 2 page tree, 3 page, 4 content, then the logo
+	// and its mask, then the caption font.
+	fontObj := 5 + len(images)
+	var fonts []string
+	if d.o.Caption != "" {
+		f := newPDFFont()
+		fontSize, advance := captionLayout(d.o.Caption, size, capHeight)
+		fmt.Fprintf(&content, "BT /F1 %s Tf %s rg %s %s Td %s Tj ET\n",
+			fnum(fontSize), pdfColor(d.o.Foreground),
+			fnum((float64(size)-advance)/2), fnum(float64(capHeight)*0.35), f.text(d.o.Caption))
+		var err error
+		if fonts, err = f.objects(fontObj); err != nil {
+			return nil, err
+		}
+		resources += fmt.Sprintf(" /Font << /F1 %d 0 R >>", fontObj)
+	}
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources <<%s >> /Contents 4 0 R >>", size, height, resources),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+	objects = append(objects, images...)
+	return writePDF(append(objects, fonts...)), nil
+}
+
+// pdfCode fills the modules with the top-left corner of the code at
+// (left, top) in page coordinates and scale points per module. Module units
+// are flipped so row 0 is at the top.
+func (d *drawing) pdfCode(b *bytes.Buffer, left, top, scale float64) {
+	fmt.Fprintf(b, "q %s 0 0 -%s %s %s cm %s rg\n", fnum(scale), fnum(scale), fnum(left), fnum(top), pdfColor(d.o.Foreground))
+	if d.o.Rounded {
+		for y := range d.m {
+			for x := range d.m[y] {
+				if d.m[y][x] {
+					pdfModule(b, x, y, d.corners(x, y))
+				}
+			}
+		}
+	} else {
+		d.m.runs(func(x, y, length int) {
+			fmt.Fprintf(b, "%d %d %d 1 re\n", x, y, length)
+		})
+	}
+	b.WriteString("f Q\n")
+}
+
+// pdfModule outlines one module in flipped module units, replacing rounded
+// corners with Bezier quarter circles.
+func pdfModule(b *bytes.Buffer, x, y int, c corners) {
+	fx, fy := float64(x), float64(y)
+	r := func(rounded bool) float64 {
+		if rounded {
+			return 0.5
+		}
+		return 0
+	}
+	tl, tr, br, bl := r(c.tl), r(c.tr), r(c.br), r(c.bl)
+	k := 0.5 * bezierK
+	fmt.Fprintf(b, "%s %s m\n", fnum(fx+tl), fnum(fy))
+	fmt.Fprintf(b, "%s %s l\n", fnum(fx+1-tr), fnum(fy))
+	if c.tr {
+		fmt.Fprintf(b, "%s %s %s %s %s %s c\n", fnum(fx+1-tr+k), fnum(fy), fnum(fx+1), fnum(fy+tr-k), fnum(fx+1), fnum(fy+tr))
+	}
+	fmt.Fprintf(b, "%s %s l\n", fnum(fx+1), fnum(fy+1-br))
+	if c.br {
+		fmt.Fprintf(b, "%s %s %s %s %s %s c\n", fnum(fx+1), fnum(fy+1-br+k), fnum(fx+1-br+k), fnum(fy+1), fnum(fx+1-br), fnum(fy+1))
+	}
+	fmt.Fprintf(b, "%s %s l\n", fnum(fx+bl), fnum(fy+1))
+	if c.bl {
+		fmt.Fprintf(b, "%s %s %s %s %s %s c\n", fnum(fx+bl-k), fnum(fy+1), fnum(fx), fnum(fy+1-bl+k), fnum(fx), fnum(fy+1-bl))
+	}
+	fmt.Fprintf(b, "%s %s l\n", fnum(fx), fnum(fy+tl))
+	if c.tl {
+		fmt.Fprintf(b, "%s %s %s %s %s %s c\n", fnum(fx), fnum(fy+tl-k), fnum(fx+tl-k), fnum(fy), fnum(fx+tl), fnum(fy))
+	}
+	b.WriteString("h\n")
+}
+
+// pdfImage returns the image XObject and its alpha soft mask, which is
+// object number maskObj, both deflated. The logo is downscaled first so the
+// PDF stays small.
+func pdfImage(img image.Image, maskObj int) ([]string, error) {
+	b := img.Bounds()
+	if b.Dx() > 512 || b.Dy() > 512 {
+		s := 512 / float64(max(b.Dx(), b.Dy()))
+		img = scaleImage(img, int(float64(b.Dx())*s), int(float64(b.Dy())*s))
+		b = img.Bounds()
+	}
+	rgb := make([]byte, 0, b.Dx()*b.Dy()*3)
+	alpha := make([]byte, 0, b.Dx()*b.Dy())
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+		}
+	}
+	rgbData, err := deflate(rgb)
+	if err != nil {
+		return nil, err
+	}
+	alphaData, err := deflate(alpha)
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode /SMask %d 0 R /Length %d >>\nstream\n%s\nendstream",
+			b.Dx(), b.Dy(), maskObj, len(rgbData), rgbData),
+		fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream",
+			b.Dx(), b.Dy(), len(alphaData), alphaData),
+	}, nil
+}
+
+func deflate(data []byte) ([]byte, error) {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// writePDF lays out numbered objects (the first is the catalog) with the
+// cross-reference table PDF readers need to find them.
+func writePDF(objects []string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+func pdfColor(c color.RGBA) string {
+	return fnum(float64(c.R)/255) + " " + fnum(float64(c.G)/255) + " " + fnum(float64(c.B)/255)
+}
+
+// fnum formats a PDF number without exponent notation.
+func fnum(f float64) string {
+	return strconv.FormatFloat(f, 'f', 4, 64)
+}
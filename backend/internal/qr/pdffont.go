@@ -0,0 +1,336 @@
+package qr
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// pdfFont writes text in the caption font as a Type0 font with Identity-H
+// encoding, so any character the font has prints and can be copied out of
+// the PDF. Only the glyphs text used are embedded.
+type pdfFont struct {
+	buf    sfnt.Buffer
+	glyphs map[sfnt.GlyphIndex]rune
+}
+
+func newPDFFont() *pdfFont {
+	return &pdfFont{glyphs: map[sfnt.GlyphIndex]rune{}}
+}
+
+// text returns s as a hex string of glyph IDs for the Tj operator. Control
+// characters are dropped and characters the font lacks print as .notdef.
+func (f *pdfFont) text(s string) string {
+	fnt := loadCaptionFont()
+	var b strings.Builder
+	b.WriteByte('<')
+	for _, r := range s {
+		if r < 0x20 {
+			continue
+		}
+		g, err := fnt.GlyphIndex(&f.buf, r)
+		if err != nil {
+			g = 0
+		}
+		if _, ok := f.glyphs[g]; !ok && g != 0 {
+			f.glyphs[g] = r
+		}
+		fmt.Fprintf(&b, "%04X", uint16(g))
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+// objects returns the font dictionary, numbered first, followed by the
+// objects it refers to.
+func (f *pdfFont) objects(first int) ([]string, error) {
+	fnt := loadCaptionFont()
+	upem := fixed.I(int(fnt.UnitsPerEm()))
+	scale := func(v fixed.Int26_6) int { return int(v) * 1000 / int(upem) }
+
+	gids := make([]sfnt.GlyphIndex, 0, len(f.glyphs))
+	for g := range f.glyphs {
+		gids = append(gids, g)
+	}
+	sort.Slice(gids, func(i, j int) bool { return gids[i] < gids[j] })
+
+	var widths, cmap strings.Builder
+	for i, g := range gids {
+		adv, err := fnt.GlyphAdvance(&f.buf, g, upem, font.HintingNone)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&widths, "%d [%d] ", g, scale(adv))
+		if i%100 == 0 {
+			if i > 0 {
+				cmap.WriteString("endbfchar\n")
+			}
+			fmt.Fprintf(&cmap, "%d beginbfchar\n", min(100, len(gids)-i))
+		}
+		fmt.Fprintf(&cmap, "<%04X> <", uint16(g))
+		for _, u := range utf16.Encode([]rune{f.glyphs[g]}) {
+			fmt.Fprintf(&cmap, "%04X", u)
+		}
+		cmap.WriteString(">\n")
+	}
+	if len(gids) > 0 {
+		cmap.WriteString("endbfchar\n")
+	}
+	toUnicode := "/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n" +
+		cmap.String() + "endcmap\nCMapName currentdict /CMapResource defineresource pop\nend\nend\n"
+
+	sub, err := subsetTTF(captionTTF, gids)
+	if err != nil {
+		return nil, err
+	}
+	fontFile, err := deflate(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := fnt.Metrics(&f.buf, upem, font.HintingNone)
+	if err != nil {
+		return nil, err
+	}
+	bounds, err := fnt.Bounds(&f.buf, upem, font.HintingNone)
+	if err != nil {
+		return nil, err
+	}
+	capHeight := m.CapHeight
+	if capHeight < 0 {
+		// sfnt reports it in y-down coordinates.
+		capHeight = -capHeight
+	}
+	// A subset's name carries a six-letter tag; it only has to differ
+	// between subsets within one file.
+	name := "QRCAPT+DejaVuSans"
+	return []string{
+		fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+			name, first+1, first+4),
+		fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /DW 1000 /W [%s] >>",
+			name, first+2, strings.TrimSpace(widths.String())),
+		fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+			name, scale(bounds.Min.X), -scale(bounds.Max.Y), scale(bounds.Max.X), -scale(bounds.Min.Y),
+			scale(m.Ascent), -scale(m.Descent), scale(capHeight), first+3),
+		fmt.Sprintf("<< /Length %d /Length1 %d /Filter /FlateDecode >>\nstream\n%s\nendstream", len(fontFile), len(sub), fontFile),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", len(toUnicode), toUnicode),
+	}, nil
+}
+
+// subsetTables are the TrueType tables a PDF viewer needs to draw glyphs
+// by ID, plus cmap and post, which some viewers check for. Names and layout
+// tables are left out.
+var subsetTables = []string{"cmap", "cvt ", "fpgm", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "post", "prep"}
+
+// subsetTTF keeps the outlines of .notdef, keep and the glyphs their
+// composites are built from and empties every other glyph. Glyph IDs do not
+// change, so the text and widths written against the full font still match.
+func subsetTTF(ttf []byte, keep []sfnt.GlyphIndex) ([]byte, error) {
+	tables, err := ttfTables(ttf)
+	if err != nil {
+		return nil, err
+	}
+	head, maxp := tables["head"], tables["maxp"]
+	loca, glyf := tables["loca"], tables["glyf"]
+	if len(head) < 54 || len(maxp) < 6 || glyf == nil {
+		return nil, fmt.Errorf("font is missing glyph tables")
+	}
+	numGlyphs := int(binary.BigEndian.Uint16(maxp[4:]))
+	longLoca := binary.BigEndian.Uint16(head[50:]) == 1
+	if (longLoca && len(loca) < 4*(numGlyphs+1)) || (!longLoca && len(loca) < 2*(numGlyphs+1)) {
+		return nil, fmt.Errorf("font loca table is truncated")
+	}
+	glyph := func(g int) []byte {
+		var start, end int
+		if longLoca {
+			start, end = int(binary.BigEndian.Uint32(loca[4*g:])), int(binary.BigEndian.Uint32(loca[4*g+4:]))
+		} else {
+			start, end = 2*int(binary.BigEndian.Uint16(loca[2*g:])), 2*int(binary.BigEndian.Uint16(loca[2*g+2:]))
+		}
+		if start >= end || end > len(glyf) {
+			return nil
+		}
+		return glyf[start:end]
+	}
+
+	used := map[int]bool{0: true}
+	queue := []int{0}
+	for _, g := range keep {
+		if int(g) < numGlyphs && !used[int(g)] {
+			used[int(g)] = true
+			queue = append(queue, int(g))
+		}
+	}
+	for len(queue) > 0 {
+		g := queue[0]
+		queue = queue[1:]
+		for _, c := range compositeParts(glyph(g)) {
+			if c < numGlyphs && !used[c] {
+				used[c] = true
+				queue = append(queue, c)
+			}
+		}
+	}
+
+	var newGlyf bytes.Buffer
+	newLoca := make([]byte, 4*(numGlyphs+1))
+	for g := 0; g < numGlyphs; g++ {
+		binary.BigEndian.PutUint32(newLoca[4*g:], uint32(newGlyf.Len()))
+		if used[g] {
+			newGlyf.Write(glyph(g))
+			for newGlyf.Len()%4 != 0 {
+				newGlyf.WriteByte(0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(newLoca[4*numGlyphs:], uint32(newGlyf.Len()))
+
+	newHead := append([]byte(nil), head...)
+	binary.BigEndian.PutUint32(newHead[8:], 0)
+	binary.BigEndian.PutUint16(newHead[50:], 1)
+	tables["head"], tables["loca"], tables["glyf"] = newHead, newLoca, newGlyf.Bytes()
+	if post := tables["post"]; len(post) >= 32 {
+		// Version 3 keeps the metrics but drops the glyph names.
+		post = append([]byte(nil), post[:32]...)
+		binary.BigEndian.PutUint32(post, 0x00030000)
+		tables["post"] = post
+	}
+
+	out := writeTTF(tables)
+	// checkSumAdjustment makes the whole file sum to a fixed constant.
+	binary.BigEndian.PutUint32(out[ttfTableOffset(out, "head")+8:], 0xB1B0AFBA-ttfChecksum(out))
+	return out, nil
+}
+
+// compositeParts lists the glyphs a composite glyph is assembled from.
+func compositeParts(g []byte) []int {
+	if len(g) < 10 || int16(binary.BigEndian.Uint16(g)) >= 0 {
+		return nil
+	}
+	const (
+		argsAreWords   = 0x0001
+		haveScale      = 0x0008
+		moreComponents = 0x0020
+		haveXYScale    = 0x0040
+		have2x2        = 0x0080
+	)
+	var parts []int
+	for p := 10; p+4 <= len(g); {
+		flags := binary.BigEndian.Uint16(g[p:])
+		parts = append(parts, int(binary.BigEndian.Uint16(g[p+2:])))
+		p += 4
+		if flags&argsAreWords != 0 {
+			p += 4
+		} else {
+			p += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			p += 2
+		case flags&haveXYScale != 0:
+			p += 4
+		case flags&have2x2 != 0:
+			p += 8
+		}
+		if flags&moreComponents == 0 {
+			break
+		}
+	}
+	return parts
+}
+
+// ttfTables returns the subsetTables present in a TrueType file.
+func ttfTables(ttf []byte) (map[string][]byte, error) {
+	if len(ttf) < 12 {
+		return nil, fmt.Errorf("font is truncated")
+	}
+	n := int(binary.BigEndian.Uint16(ttf[4:]))
+	if len(ttf) < 12+16*n {
+		return nil, fmt.Errorf("font table directory is truncated")
+	}
+	tables := map[string][]byte{}
+	for i := 0; i < n; i++ {
+		rec := ttf[12+16*i:]
+		tag := string(rec[:4])
+		off, length := int(binary.BigEndian.Uint32(rec[8:])), int(binary.BigEndian.Uint32(rec[12:]))
+		if off+length > len(ttf) {
+			return nil, fmt.Errorf("font table %q is truncated", tag)
+		}
+		for _, want := range subsetTables {
+			if tag == want {
+				tables[tag] = ttf[off : off+length]
+			}
+		}
+	}
+	return tables, nil
+}
+
+// writeTTF lays tables out in tag order after the table directory, each
+// padded to four bytes as the format requires.
+func writeTTF(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	n := len(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= n {
+		entrySelector++
+	}
+	searchRange := 16 << entrySelector
+
+	dir := make([]byte, 12+16*n)
+	binary.BigEndian.PutUint32(dir, 0x00010000)
+	binary.BigEndian.PutUint16(dir[4:], uint16(n))
+	binary.BigEndian.PutUint16(dir[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(dir[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(dir[10:], uint16(16*n-searchRange))
+
+	var body bytes.Buffer
+	for i, tag := range tags {
+		data := tables[tag]
+		rec := dir[12+16*i:]
+		copy(rec, tag)
+		binary.BigEndian.PutUint32(rec[4:], ttfChecksum(data))
+		binary.BigEndian.PutUint32(rec[8:], uint32(len(dir)+body.Len()))
+		binary.BigEndian.PutUint32(rec[12:], uint32(len(data)))
+		body.Write(data)
+		for body.Len()%4 != 0 {
+			body.WriteByte(0)
+		}
+	}
+	return append(dir, body.Bytes()...)
+}
+
+func ttfTableOffset(ttf []byte, tag string) int {
+	n := int(binary.BigEndian.Uint16(ttf[4:]))
+	for i := 0; i < n; i++ {
+		rec := ttf[12+16*i:]
+		if string(rec[:4]) == tag {
+			return int(binary.BigEndian.Uint32(rec[8:]))
+		}
+	}
+	return -1
+}
+
+func ttfChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
@@ -0,0 +1,48 @@
+package qr
+
+import (
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// Vietnamese letters with two marks are composite glyphs, so the subset has
+// to keep their parts as well or they print blank.
+func TestSubsetKeepsVietnameseGlyphs(t *testing.T) {
+	f := newPDFFont()
+	f.text("Trường THPT Nguyễn Huệ – Phòng ếữỹ")
+	gids := make([]sfnt.GlyphIndex, 0, len(f.glyphs))
+	for g := range f.glyphs {
+		gids = append(gids, g)
+	}
+	sub, err := subsetTTF(captionTTF, gids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sub) > len(captionTTF)/4 {
+		t.Errorf("subset is %d bytes, full font %d", len(sub), len(captionTTF))
+	}
+	if sum := ttfChecksum(sub); sum != 0xB1B0AFBA {
+		t.Errorf("file checksum = %#x", sum)
+	}
+	parsed, err := sfnt.Parse(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf sfnt.Buffer
+	ppem := fixed.I(int(parsed.UnitsPerEm()))
+	for g, r := range f.glyphs {
+		if r == ' ' {
+			continue
+		}
+		segs, err := parsed.LoadGlyph(&buf, g, ppem, nil)
+		if err != nil || len(segs) == 0 {
+			t.Errorf("glyph %d for %q is empty: %v", g, r, err)
+		}
+	}
+	if _, err := parsed.GlyphAdvance(&buf, gids[0], ppem, font.HintingNone); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,172 @@
+// Package qr renders QR codes as SVG, PNG or PDF on request, so nothing but
+// the encoded text has to be stored.
+package qr
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"unicode"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	FormatSVG = "svg"
+	FormatPNG = "png"
+	FormatPDF = "pdf"
+)
+
+const (
+	MinSize = 64
+	MaxSize = 2048
+
+	MaxMargin = 16
+
+	MaxCaptionLen = 60
+)
+
+// levels maps the usual ECC letters to the encoder's recovery levels
+// (7%, 15%, 25% and 30% of the symbol may be damaged).
+var levels = map[string]qrcode.RecoveryLevel{
+	"L": qrcode.Low,
+	"M": qrcode.Medium,
+	"Q": qrcode.High,
+	"H": qrcode.Highest,
+}
+
+// Options controls how a code is drawn. Size is the width of the image in
+// pixels (points for PDF), Margin the quiet zone in modules.
+//
+// The rest is branding: module colors, rounded modules, a logo in the
+// center and a caption under the code, which makes the image taller by
+// CaptionHeight.
+type Options struct {
+	Format string
+	Size   int
+	ECC    string
+	Margin int
+
+	Foreground color.RGBA
+	Background color.RGBA
+	Rounded    bool
+	Caption    string
+	// Logo covers the middle of the code, so rendering with one always uses
+	// the highest error correction.
+	Logo image.Image
+}
+
+// DefaultOptions is a 512px black-on-white PNG with medium error correction
+// and the standard four-module quiet zone.
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Size:       512,
+		ECC:        "M",
+		Margin:     4,
+		Foreground: color.RGBA{0, 0, 0, 255},
+		Background: color.RGBA{255, 255, 255, 255},
+	}
+}
+
+func (o Options) Validate() error {
+	switch o.Format {
+	case FormatSVG, FormatPNG, FormatPDF:
+	default:
+		return fmt.Errorf("format must be svg, png or pdf")
+	}
+	if o.Size < MinSize || o.Size > MaxSize {
+		return fmt.Errorf("size must be between %d and %d", MinSize, MaxSize)
+	}
+	if _, ok := levels[o.ECC]; !ok {
+		return fmt.Errorf("ecc must be L, M, Q or H")
+	}
+	if o.Margin < 0 || o.Margin > MaxMargin {
+		return fmt.Errorf("margin must be between 0 and %d", MaxMargin)
+	}
+	if err := CheckContrast(o.Foreground, o.Background); err != nil {
+		return err
+	}
+	if len([]rune(o.Caption)) > MaxCaptionLen {
+		return fmt.Errorf("caption must be at most %d characters", MaxCaptionLen)
+	}
+	for _, r := range o.Caption {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("caption must be a single line of text")
+		}
+	}
+	return nil
+}
+
+// CaptionHeight is the extra height below the code when there is a caption.
+func (o Options) CaptionHeight() int {
+	if o.Caption == "" {
+		return 0
+	}
+	return o.Size / 8
+}
+
+// ContentType is the MIME type of the rendered format.
+func ContentType(format string) string {
+	switch format {
+	case FormatSVG:
+		return "image/svg+xml"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "image/png"
+	}
+}
+
+// Matrix is a square grid of modules including the quiet zone;
+// m[y][x] is true for a dark module.
+type Matrix [][]bool
+
+// Encode builds the module matrix for content with margin light modules
+// on every side.
+func Encode(content, ecc string, margin int) (Matrix, error) {
+	level, ok := levels[strings.ToUpper(ecc)]
+	if !ok {
+		return nil, fmt.Errorf("unknown ecc level %q", ecc)
+	}
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, err
+	}
+	code.DisableBorder = true
+	symbol := code.Bitmap()
+
+	n := len(symbol) + 2*margin
+	m := make(Matrix, n)
+	for y := range m {
+		m[y] = make([]bool, n)
+	}
+	for y, row := range symbol {
+		copy(m[y+margin][margin:], row)
+	}
+	return m, nil
+}
+
+// Render encodes content and draws it in the requested format.
+func Render(content string, o Options) ([]byte, error) {
+	if o.Logo != nil {
+		o.ECC = "H"
+	}
+	if err := o.Validate(); err != nil {
+		return nil, err
+	}
+	m, err := Encode(content, o.ECC, o.Margin)
+	if err != nil {
+		return nil, err
+	}
+	d := newDrawing(m, o)
+	switch o.Format {
+	case FormatSVG:
+		return d.SVG()
+	case FormatPDF:
+		return d.PDF()
+	default:
+		return d.PNG()
+	}
+}
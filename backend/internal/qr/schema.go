@@ -0,0 +1,59 @@
+package qr
+
+// Field describes one payload field for clients building forms.
+type Field struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"` // string, number, boolean or datetime (RFC 3339)
+	Required    bool     `json:"required"`
+	MaxLength   int      `json:"max_length,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// Schema lists the fields of one payload type. The rules themselves live in
+// each type's Validate.
+type Schema struct {
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Fields      []Field `json:"fields"`
+}
+
+// Schemas describes every payload type, in display order.
+func Schemas() []Schema {
+	contact := []Field{
+		{Name: "name", Type: "string", Required: true, MaxLength: 100},
+		{Name: "phone", Type: "string", MaxLength: 20, Description: "phone or email is required"},
+		{Name: "email", Type: "string", MaxLength: 254, Description: "phone or email is required"},
+		{Name: "org", Type: "string", MaxLength: 100},
+		{Name: "title", Type: "string", MaxLength: 100, Description: "vCard only"},
+		{Name: "url", Type: "string", MaxLength: 500},
+		{Name: "address", Type: "string", MaxLength: 300},
+		{Name: "note", Type: "string", MaxLength: 500},
+	}
+	return []Schema{
+		{Type: PayloadWiFi, Description: "Join a Wi-Fi network", Fields: []Field{
+			{Name: "ssid", Type: "string", Required: true, MaxLength: 32},
+			{Name: "security", Type: "string", Enum: []string{"WPA", "WEP", "nopass"}, Description: "defaults to WPA"},
+			{Name: "password", Type: "string", MaxLength: 63, Description: "8-63 characters for WPA, empty for nopass"},
+			{Name: "hidden", Type: "boolean"},
+		}},
+		{Type: PayloadVCard, Description: "Contact card (vCard 3.0)", Fields: contact},
+		{Type: PayloadMeCard, Description: "Contact card (MECARD, smaller code)", Fields: contact},
+		{Type: PayloadEvent, Description: "Calendar event (VEVENT)", Fields: []Field{
+			{Name: "summary", Type: "string", Required: true, MaxLength: 200},
+			{Name: "start", Type: "datetime", Required: true},
+			{Name: "end", Type: "datetime", Required: true, Description: "after start"},
+			{Name: "location", Type: "string", MaxLength: 200},
+			{Name: "description", Type: "string", MaxLength: 500},
+		}},
+		{Type: PayloadGeo, Description: "Location on a map", Fields: []Field{
+			{Name: "latitude", Type: "number", Required: true, Description: "-90 to 90"},
+			{Name: "longitude", Type: "number", Required: true, Description: "-180 to 180"},
+			{Name: "query", Type: "string", MaxLength: 200, Description: "label shown by map apps"},
+		}},
+		{Type: PayloadSMS, Description: "Prefilled text message", Fields: []Field{
+			{Name: "phone", Type: "string", Required: true, MaxLength: 20},
+			{Name: "message", Type: "string", MaxLength: 300},
+		}},
+	}
+}
@@ -0,0 +1,111 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// A4 sheet layout in points: three columns by four rows of cut-out cards.
+const (
+	a4Width     = 595.0
+	a4Height    = 842.0
+	sheetMargin = 36.0
+	sheetCols   = 3
+	sheetRows   = 4
+	sheetCode   = 150.0
+	labelSize   = 10.0
+)
+
+// SheetItem is one code on a printable sheet.
+type SheetItem struct {
+	Label   string
+	Content string
+}
+
+// Sheet lays codes out on A4 pages with each label underneath and dashed
+// cut lines between cards. Of o only ECC, Margin, the colors and Rounded are
+// used; Size, logos and captions do not apply.
+func Sheet(items []SheetItem, o Options) ([]byte, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("no codes to print")
+	}
+	if err := CheckContrast(o.Foreground, o.Background); err != nil {
+		return nil, err
+	}
+	cellW := (a4Width - 2*sheetMargin) / sheetCols
+	cellH := (a4Height - 2*sheetMargin) / sheetRows
+	perPage := sheetCols * sheetRows
+	pages := (len(items) + perPage - 1) / perPage
+
+	// Objects: 1 catalog, 2 page tree, then a page and its content stream
+	// for every page, then the label font.
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+	}
+	fontObj := len(objects) + 2*pages + 1
+	f := newPDFFont()
+	var kids bytes.Buffer
+	for p := 0; p < pages; p++ {
+		var content bytes.Buffer
+		for i := p * perPage; i < len(items) && i < (p+1)*perPage; i++ {
+			slot := i - p*perPage
+			left := sheetMargin + float64(slot%sheetCols)*cellW
+			top := a4Height - sheetMargin - float64(slot/sheetCols)*cellH
+			if err := sheetCard(&content, f, items[i], o, left, top, cellW, cellH); err != nil {
+				return nil, fmt.Errorf("%s: %w", items[i].Label, err)
+			}
+		}
+		pageObj := len(objects) + 1
+		fmt.Fprintf(&kids, "%d 0 R ", pageObj)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+				fnum(a4Width), fnum(a4Height), fontObj, pageObj+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", bytes.TrimSpace(kids.Bytes()), pages)
+	fonts, err := f.objects(fontObj)
+	if err != nil {
+		return nil, err
+	}
+	return writePDF(append(objects, fonts...)), nil
+}
+
+// sheetCard draws one card whose top-left corner is (left, top), writing
+// the label in f.
+func sheetCard(b *bytes.Buffer, f *pdfFont, item SheetItem, o Options, left, top, w, h float64) error {
+	m, err := Encode(item.Content, o.ECC, o.Margin)
+	if err != nil {
+		return err
+	}
+	d := newDrawing(m, Options{Foreground: o.Foreground, Background: o.Background, Rounded: o.Rounded})
+
+	fmt.Fprintf(b, "q 0.75 G 0.5 w [3 3] 0 d %s %s %s %s re S Q\n", fnum(left), fnum(top-h), fnum(w), fnum(h))
+	codeLeft, codeTop := left+(w-sheetCode)/2, top-8
+	fmt.Fprintf(b, "%s rg %s %s %s %s re f\n", pdfColor(o.Background), fnum(codeLeft), fnum(codeTop-sheetCode), fnum(sheetCode), fnum(sheetCode))
+	d.pdfCode(b, codeLeft, codeTop, sheetCode/float64(d.n))
+
+	label, size := fitLabel(item.Label, w-12)
+	advance := measureCaption(label, size)
+	fmt.Fprintf(b, "BT /F1 %s Tf 0 g %s %s Td %s Tj ET\n",
+		fnum(size), fnum(left+(w-advance)/2), fnum(codeTop-sheetCode-16), f.text(label))
+	return nil
+}
+
+// fitLabel shrinks the label font down to 7pt and then shortens the text
+// with an ellipsis until it fits width.
+func fitLabel(label string, width float64) (string, float64) {
+	size := labelSize
+	if adv := measureCaption(label, size); adv > width {
+		size = max(7, size*width/adv)
+	}
+	if measureCaption(label, size) <= width {
+		return label, size
+	}
+	runes := []rune(label)
+	for len(runes) > 1 && measureCaption(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "...", size
+}
@@ -0,0 +1,58 @@
+package qr
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MinContrast is the lowest foreground/background contrast ratio accepted,
+// the WCAG AA level for text; phone cameras in a dim classroom need at
+// least that.
+const MinContrast = 4.5
+
+// ParseColor reads an opaque "#rrggbb" or "rrggbb" color.
+func ParseColor(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) != 6 {
+		return color.RGBA{}, fmt.Errorf("color %q must be six hex digits", s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("color %q must be six hex digits", s)
+	}
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 255}, nil
+}
+
+// Hex formats c as "#rrggbb".
+func Hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// CheckContrast refuses color pairs that scanners struggle with: too little
+// contrast, or light modules on a dark background, which many readers do
+// not try.
+func CheckContrast(fg, bg color.RGBA) error {
+	lf, lb := luminance(fg), luminance(bg)
+	if lf >= lb {
+		return fmt.Errorf("foreground must be darker than background")
+	}
+	if ratio := (lb + 0.05) / (lf + 0.05); ratio < MinContrast {
+		return fmt.Errorf("colors %s on %s have contrast %.1f:1, need at least %.1f:1", Hex(fg), Hex(bg), ratio, MinContrast)
+	}
+	return nil
+}
+
+// luminance is the WCAG relative luminance of an sRGB color.
+func luminance(c color.RGBA) float64 {
+	channel := func(v uint8) float64 {
+		f := float64(v) / 255
+		if f <= 0.03928 {
+			return f / 12.92
+		}
+		return math.Pow((f+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.R) + 0.7152*channel(c.G) + 0.0722*channel(c.B)
+}
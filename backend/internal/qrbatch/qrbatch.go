@@ -0,0 +1,196 @@
+// Package qrbatch creates many link QR codes from a CSV file and exports them
+// as a ZIP of images or a printable A4 sheet. It backs both the bulk endpoint
+// and the `server qr-bulk` command.
+package qrbatch
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"edu-web-backend/internal/models"
+	"edu-web-backend/internal/qr"
+	"edu-web-backend/internal/repository"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MaxRows bounds one upload, which is created in a single transaction.
+const MaxRows = 500
+
+// RowError points at a CSV line that cannot be imported.
+type RowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ErrInvalidRows is returned with the row errors when any row is invalid;
+// nothing is created then.
+var ErrInvalidRows = errors.New("csv has invalid rows")
+
+// ParseCSV reads label,target_url,type rows. A header row is optional and
+// type defaults to general. Payload types are not supported here since
+// their fields do not fit a CSV column.
+func ParseCSV(r io.Reader) ([]models.QRCode, []RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var codes []models.QRCode
+	var rowErrs []RowError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(codes)+len(rowErrs) == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "label") {
+			continue
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		if len(codes)+len(rowErrs) >= MaxRows {
+			return nil, nil, fmt.Errorf("csv has more than %d rows", MaxRows)
+		}
+
+		code, err := parseRow(record)
+		if err != nil {
+			rowErrs = append(rowErrs, RowError{Line: line, Error: err.Error()})
+			continue
+		}
+		codes = append(codes, code)
+	}
+	if len(rowErrs) > 0 {
+		return nil, rowErrs, ErrInvalidRows
+	}
+	if len(codes) == 0 {
+		return nil, nil, fmt.Errorf("csv has no rows")
+	}
+	return codes, nil, nil
+}
+
+func parseRow(record []string) (models.QRCode, error) {
+	if len(record) < 2 || len(record) > 3 {
+		return models.QRCode{}, fmt.Errorf("expected label,target_url[,type]")
+	}
+	code := models.QRCode{
+		Label:      strings.TrimSpace(record[0]),
+		TargetURL:  strings.TrimSpace(record[1]),
+		Type:       "general",
+		Visibility: models.QRPublic,
+	}
+	if len(record) == 3 && strings.TrimSpace(record[2]) != "" {
+		code.Type = strings.TrimSpace(record[2])
+	}
+	if code.Label == "" || len(code.Label) > 255 {
+		return code, fmt.Errorf("label must be 1-255 characters")
+	}
+	if len(code.Type) > 50 {
+		return code, fmt.Errorf("type must be at most 50 characters")
+	}
+	if qr.IsPayloadType(code.Type) {
+		return code, fmt.Errorf("type %s needs a payload; create it with POST /qrcodes/generate", code.Type)
+	}
+	if err := qr.ValidateTargetURL(code.TargetURL); err != nil {
+		return code, err
+	}
+	return code, nil
+}
+
+// Create stores the codes in one transaction and fills in their short links.
+func Create(ctx context.Context, db *repository.DB, codes []models.QRCode, publicURL string) ([]models.QRCode, error) {
+	saved, err := db.SaveQRCodes(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	for i := range saved {
+		saved[i].ShortURL = publicURL + "/q/" + saved[i].Slug
+	}
+	return saved, nil
+}
+
+// ZIP writes one image per code plus a codes.csv manifest mapping files to
+// ids and short links.
+func ZIP(w io.Writer, codes []models.QRCode, opts qr.Options) error {
+	zw := zip.NewWriter(w)
+	var manifest bytes.Buffer
+	mw := csv.NewWriter(&manifest)
+	mw.Write([]string{"file", "id", "label", "type", "target_url", "short_url"})
+
+	for i, code := range codes {
+		img, err := qr.Render(code.ShortURL, opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", code.Label, err)
+		}
+		name := fmt.Sprintf("%03d-%s.%s", i+1, fileSlug(code.Label), opts.Format)
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(img); err != nil {
+			return err
+		}
+		mw.Write([]string{name, fmt.Sprint(code.ID), csvCell(code.Label), csvCell(code.Type), csvCell(code.TargetURL), code.ShortURL})
+	}
+
+	mw.Flush()
+	if err := mw.Error(); err != nil {
+		return err
+	}
+	f, err := zw.Create("codes.csv")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(manifest.Bytes()); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// Sheet renders the codes on printable A4 pages with their labels.
+func Sheet(codes []models.QRCode, opts qr.Options) ([]byte, error) {
+	items := make([]qr.SheetItem, len(codes))
+	for i, code := range codes {
+		items[i] = qr.SheetItem{Label: code.Label, Content: code.ShortURL}
+	}
+	return qr.Sheet(items, opts)
+}
+
+// csvCell prefixes text that Excel or Sheets would run as a formula with an
+// apostrophe, which they show as plain text.
+func csvCell(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
+
+// fileSlug keeps ASCII letters and digits of a label for a file name.
+func fileSlug(label string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(label) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 40 {
+			break
+		}
+	}
+	s := strings.TrimSuffix(b.String(), "-")
+	if s == "" {
+		return "qr"
+	}
+	return s
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"edu-web-backend/internal/repository"
+	"log"
+	"sync"
+	"time"
+)
+
+// PostgresStore keeps buckets in the rate_limits table so limits hold across
+// server instances and restarts.
+type PostgresStore struct {
+	db *repository.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewPostgresStore(db *repository.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Allow(ctx context.Context, key string, p Policy, now time.Time) (Result, error) {
+	s.sweep(ctx, now)
+
+	tat, allowed, err := s.db.TakeRateLimitToken(ctx, key, now, p.interval(), p.Per)
+	if err != nil {
+		return Result{}, err
+	}
+	// tat is what is stored after the request: the advanced time when it was
+	// allowed, the untouched one when it was not.
+	if tat.Before(now) {
+		tat = now
+	}
+	res := Result{Allowed: allowed, Limit: p.Burst, Reset: tat.Sub(now)}
+	if allowed {
+		res.Remaining = int((p.Per - tat.Sub(now)) / p.interval())
+	} else {
+		res.RetryAfter = tat.Add(p.interval()).Sub(now) - p.Per
+	}
+	return res, nil
+}
+
+// sweep deletes full buckets every ten minutes.
+func (s *PostgresStore) sweep(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	due := now.Sub(s.lastSweep) >= 10*time.Minute
+	if due {
+		s.lastSweep = now
+	}
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+	if _, err := s.db.DeleteIdleRateLimits(ctx, now); err != nil {
+		log.Printf("rate limit sweep: %v", err)
+	}
+}
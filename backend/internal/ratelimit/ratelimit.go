@@ -0,0 +1,66 @@
+// Package ratelimit implements per-key token buckets for abuse protection on
+// public endpoints.
+//
+// A bucket is stored as its theoretical arrival time (the GCRA form of a token
+// bucket): one timestamp per key, which keeps the Postgres store to a single
+// atomic upsert.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Policy allows Burst requests at once, refilled evenly over Per.
+type Policy struct {
+	Name  string
+	Burst int
+	Per   time.Duration
+}
+
+// interval is the time it takes to refill one token.
+func (p Policy) interval() time.Duration {
+	return p.Per / time.Duration(p.Burst)
+}
+
+// String formats the policy for the RateLimit-Policy header, e.g. "20;w=60".
+func (p Policy) String() string {
+	return fmt.Sprintf("%d;w=%d", p.Burst, int(p.Per.Seconds()))
+}
+
+// Result is the state of a bucket after a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next request would be allowed; zero when allowed.
+	RetryAfter time.Duration
+}
+
+type Store interface {
+	// Allow takes one token from the key's bucket if there is one.
+	Allow(ctx context.Context, key string, p Policy, now time.Time) (Result, error)
+}
+
+// decide applies the GCRA rule to a bucket whose theoretical arrival time is
+// tat. It returns the new tat to store when the request is allowed.
+func decide(p Policy, tat, now time.Time) (Result, time.Time) {
+	interval := p.interval()
+	if tat.Before(now) {
+		tat = now
+	}
+	newTAT := tat.Add(interval)
+	res := Result{Limit: p.Burst}
+	if newTAT.Sub(now) > p.Per {
+		res.RetryAfter = newTAT.Sub(now) - p.Per
+		res.Reset = tat.Sub(now)
+		return res, tat
+	}
+	res.Allowed = true
+	res.Reset = newTAT.Sub(now)
+	res.Remaining = int((p.Per - newTAT.Sub(now)) / interval)
+	return res, newTAT
+}
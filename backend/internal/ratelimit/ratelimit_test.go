@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecide(t *testing.T) {
+	// Three requests at once, one token back every second.
+	p := Policy{Name: "test", Burst: 3, Per: 3 * time.Second}
+	t0 := time.Date(2024, 9, 5, 8, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return t0.Add(d) }
+
+	tests := []struct {
+		name    string
+		tat     time.Time
+		now     time.Time
+		want    Result
+		wantTAT time.Time
+	}{
+		{name: "new bucket", tat: time.Time{}, now: t0,
+			want:    Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second},
+			wantTAT: at(time.Second)},
+		{name: "last token of the burst", tat: at(2 * time.Second), now: t0,
+			want:    Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second},
+			wantTAT: at(3 * time.Second)},
+		{name: "burst used up", tat: at(3 * time.Second), now: t0,
+			want:    Result{Limit: 3, Reset: 3 * time.Second, RetryAfter: time.Second},
+			wantTAT: at(3 * time.Second)},
+		{name: "retry after part of a token", tat: at(3 * time.Second), now: at(500 * time.Millisecond),
+			want:    Result{Limit: 3, Reset: 2500 * time.Millisecond, RetryAfter: 500 * time.Millisecond},
+			wantTAT: at(3 * time.Second)},
+		{name: "one token refilled", tat: at(3 * time.Second), now: at(time.Second),
+			want:    Result{Allowed: true, Limit: 3, Remaining: 0, Reset: 3 * time.Second},
+			wantTAT: at(4 * time.Second)},
+		{name: "refill is capped at the burst", tat: t0, now: at(time.Minute),
+			want:    Result{Allowed: true, Limit: 3, Remaining: 2, Reset: time.Second},
+			wantTAT: at(time.Minute + time.Second)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, tat := decide(p, tc.tat, tc.now)
+			if got != tc.want {
+				t.Errorf("decide = %+v, want %+v", got, tc.want)
+			}
+			if !tat.Equal(tc.wantTAT) {
+				t.Errorf("tat = %v, want %v", tat, tc.wantTAT)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Package redact removes personal information (phone numbers, emails, ID
+// numbers, street addresses) from chat text before it is stored.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+// Rule replaces every match of Pattern with Replacement. Rules run in order,
+// so more specific patterns (12-digit CCCD) must come before looser ones (phone).
+type Rule struct {
+	Name        string `json:"name"`
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"`
+
+	re *regexp.Regexp
+}
+
+// DefaultRules covers the PII students most often paste into Buddy.
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: "email", Pattern: `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`, Replacement: "[EMAIL]"},
+		// CCCD (citizen ID) is 12 digits; old CMND is 9 digits.
+		{Name: "cccd", Pattern: `\b\d{12}\b`, Replacement: "[CCCD]"},
+		// 10-digit mobile 0[35789]x and 11-digit landline 02x, with +84/84 prefix and . - or space separators.
+		{Name: "phone", Pattern: `(?:\+84|\b84|\b0)[ .-]?(?:[35789]\d(?:[ .-]?\d){7}|2(?:[ .-]?\d){9})\b`, Replacement: "[SDT]"},
+		{Name: "cmnd", Pattern: `\b\d{9}\b`, Replacement: "[CMND]"},
+		// "12/3 duong Le Loi", "45A đường Nguyễn Trãi" - house number, a street
+		// word and a capitalised name. Only capitalised words and numbers
+		// ("3 Thang 2") count as the name, so "3 duong vong" and whatever the
+		// student writes after the address are left alone.
+		{Name: "address", Pattern: `\b\d{1,5}[a-zA-Z]?(?:/\d{1,5}[a-zA-Z]?)*,?\s+(?i:duong|đường|pho|phố)(?:\s+(?:\p{Lu}[\p{L}\d]*|\d+)){1,4}`, Replacement: "[DIA CHI]"},
+		// "ngo 12 Tran Phu", "hẻm 45/6 ..." - alley-style addresses, with the
+		// same capitalised street name when there is one.
+		{Name: "address_alley", Pattern: `(?:\b(?i:ngo|hem|kiet|so nha)|(?i:ngõ|hẻm|kiệt|số nhà))\s+\d{1,5}[a-zA-Z]?(?:/\d{1,5}[a-zA-Z]?)*(?:\s+(?:\p{Lu}[\p{L}\d]*|\d+)){0,4}`, Replacement: "[DIA CHI]"},
+	}
+}
+
+// LoadRules returns the default rules merged with a JSON rules file. A file rule
+// with the same name replaces the default; an empty pattern disables it. An
+// empty path returns the defaults unchanged.
+func LoadRules(path string) ([]Rule, error) {
+	rules := DefaultRules()
+	if path == "" {
+		return rules, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read redaction rules: %w", err)
+	}
+	var custom []Rule
+	if err := json.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("parse redaction rules: %w", err)
+	}
+
+	for _, c := range custom {
+		replaced := false
+		for i := range rules {
+			if rules[i].Name == c.Name {
+				rules[i] = c
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			rules = append(rules, c)
+		}
+	}
+
+	enabled := rules[:0]
+	for _, r := range rules {
+		if r.Pattern != "" {
+			enabled = append(enabled, r)
+		}
+	}
+	return enabled, nil
+}
+
+type Redactor struct {
+	rules []Rule
+}
+
+func New(rules []Rule) (*Redactor, error) {
+	compiled := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("redaction rule %q: %w", r.Name, err)
+		}
+		r.re = re
+		compiled = append(compiled, r)
+	}
+	return &Redactor{rules: compiled}, nil
+}
+
+// Redact returns text with every rule applied and how many matches each rule
+// replaced. Only the counts are returned, never the original values.
+func (r *Redactor) Redact(text string) (string, map[string]int) {
+	counts := map[string]int{}
+	for _, rule := range r.rules {
+		n := 0
+		text = rule.re.ReplaceAllStringFunc(text, func(string) string {
+			n++
+			return rule.Replacement
+		})
+		if n > 0 {
+			counts[rule.Name] += n
+		}
+	}
+	return text, counts
+}
+
+// RuleNames lists the active rules in a stable order, for logging.
+func (r *Redactor) RuleNames() []string {
+	names := make([]string, 0, len(r.rules))
+	for _, rule := range r.rules {
+		names = append(names, rule.Name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,65 @@
+package redact
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// TestCorpus runs the default rules against testdata/corpus.jsonl, the same
+// cases cmd/redact-check reports on.
+func TestCorpus(t *testing.T) {
+	r, err := New(DefaultRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open("testdata/corpus.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var tc struct {
+			Input    string `json:"input"`
+			Expected string `json:"expected"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &tc); err != nil {
+			t.Fatalf("line %d: %v", line, err)
+		}
+		if got, _ := r.Redact(tc.Input); got != tc.Expected {
+			t.Errorf("line %d: Redact(%q)\n got  %q\n want %q", line, tc.Input, got, tc.Expected)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRulesOverride(t *testing.T) {
+	path := t.TempDir() + "/rules.json"
+	custom := `[{"name": "cmnd", "pattern": ""}, {"name": "student_id", "pattern": "\\bHS\\d{6}\\b", "replacement": "[MA HS]"}]`
+	if err := os.WriteFile(path, []byte(custom), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := LoadRules(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := New(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, counts := r.Redact("ma HS123456, CMND 025123456")
+	if want := "ma [MA HS], CMND 025123456"; got != want {
+		t.Errorf("Redact = %q, want %q", got, want)
+	}
+	if counts["student_id"] != 1 || counts["cmnd"] != 0 {
+		t.Errorf("counts = %v", counts)
+	}
+}
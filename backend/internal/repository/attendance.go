@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"edu-web-backend/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MigrateAttendance creates QR check-in sessions and their check-ins. It runs
+// after MigrateAuth, which creates users.
+func (db *DB) MigrateAttendance(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS attendance_sessions (
+			id SERIAL PRIMARY KEY,
+			teacher_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			title VARCHAR(200) NOT NULL,
+			rotate_seconds INT NOT NULL,
+			started_at TIMESTAMP NOT NULL,
+			ends_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_attendance_sessions_teacher ON attendance_sessions(teacher_id, started_at)`,
+		`CREATE TABLE IF NOT EXISTS attendance_checkins (
+			session_id INT NOT NULL REFERENCES attendance_sessions(id) ON DELETE CASCADE,
+			user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			checked_in_at TIMESTAMP NOT NULL,
+			PRIMARY KEY (session_id, user_id)
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.pool.Exec(ctx, q); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+	return nil
+}
+
+const attendanceSessionColumns = `s.id, s.teacher_id, s.title, s.rotate_seconds, s.started_at, s.ends_at,
+	(SELECT COUNT(*) FROM attendance_checkins c WHERE c.session_id = s.id)`
+
+func scanAttendanceSession(row pgx.Row) (*models.AttendanceSession, error) {
+	var s models.AttendanceSession
+	if err := row.Scan(&s.ID, &s.TeacherID, &s.Title, &s.RotateSeconds, &s.StartedAt, &s.EndsAt, &s.CheckIns); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (db *DB) CreateAttendanceSession(ctx context.Context, s *models.AttendanceSession) error {
+	return db.pool.QueryRow(ctx,
+		`INSERT INTO attendance_sessions (teacher_id, title, rotate_seconds, started_at, ends_at)
+		 VALUES ($1,$2,$3,$4,$5) RETURNING id`,
+		s.TeacherID, s.Title, s.RotateSeconds, s.StartedAt.UTC(), s.EndsAt.UTC(),
+	).Scan(&s.ID)
+}
+
+func (db *DB) GetAttendanceSession(ctx context.Context, id int) (*models.AttendanceSession, error) {
+	s, err := scanAttendanceSession(db.pool.QueryRow(ctx,
+		`SELECT `+attendanceSessionColumns+` FROM attendance_sessions s WHERE s.id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return s, err
+}
+
+// ListAttendanceSessions returns the sessions of one teacher, newest first,
+// or of everyone when teacherID is 0.
+func (db *DB) ListAttendanceSessions(ctx context.Context, teacherID int) ([]models.AttendanceSession, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+attendanceSessionColumns+` FROM attendance_sessions s
+		 WHERE $1 = 0 OR s.teacher_id = $1
+		 ORDER BY s.started_at DESC LIMIT 200`, teacherID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sessions []models.AttendanceSession
+	for rows.Next() {
+		s, err := scanAttendanceSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+	return sessions, rows.Err()
+}
+
+// EndAttendanceSession closes a session at now unless it has already ended.
+func (db *DB) EndAttendanceSession(ctx context.Context, id int, now time.Time) error {
+	_, err := db.pool.Exec(ctx,
+		`UPDATE attendance_sessions SET ends_at = $2 WHERE id = $1 AND ends_at > $2`, id, now.UTC())
+	return err
+}
+
+// RecordCheckIn stores a student's check-in. It reports false when the
+// student had already checked in, keeping the first time.
+func (db *DB) RecordCheckIn(ctx context.Context, sessionID, userID int, now time.Time) (bool, error) {
+	tag, err := db.pool.Exec(ctx,
+		`INSERT INTO attendance_checkins (session_id, user_id, checked_in_at) VALUES ($1,$2,$3)
+		 ON CONFLICT (session_id, user_id) DO NOTHING`,
+		sessionID, userID, now.UTC(),
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (db *DB) ListCheckIns(ctx context.Context, sessionID int) ([]models.AttendanceCheckIn, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT c.session_id, c.user_id, u.username, u.display_name, c.checked_in_at
+		 FROM attendance_checkins c JOIN users u ON u.id = c.user_id
+		 WHERE c.session_id = $1 ORDER BY c.checked_in_at ASC`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var checkIns []models.AttendanceCheckIn
+	for rows.Next() {
+		var ci models.AttendanceCheckIn
+		if err := rows.Scan(&ci.SessionID, &ci.UserID, &ci.Username, &ci.DisplayName, &ci.CheckedInAt); err != nil {
+			return nil, err
+		}
+		checkIns = append(checkIns, ci)
+	}
+	return checkIns, rows.Err()
+}
@@ -0,0 +1,282 @@
+package repository
+
+import (
+	"context"
+	"edu-web-backend/internal/models"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MigrateChat creates the chat session, redaction audit and exercise tables.
+// It runs after MigrateAuth because sessions reference users.
+func (db *DB) MigrateChat(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS chat_sessions (
+			id SERIAL PRIMARY KEY,
+			session_id VARCHAR(100) UNIQUE NOT NULL DEFAULT gen_random_uuid()::text,
+			user_id INT REFERENCES users(id) ON DELETE CASCADE,
+			anon_id VARCHAR(64),
+			created_at TIMESTAMP DEFAULT NOW(),
+			CHECK (user_id IS NOT NULL OR anon_id IS NOT NULL)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_chat_sessions_user ON chat_sessions(user_id)`,
+		// Language of the last reply, used when a message does not tell.
+		`ALTER TABLE chat_sessions ADD COLUMN IF NOT EXISTS lang VARCHAR(5) NOT NULL DEFAULT ''`,
+		`CREATE INDEX IF NOT EXISTS idx_chat_sessions_anon ON chat_sessions(anon_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_chat_messages_session ON chat_messages(session_id)`,
+		// Set when the student's original message hit the crisis keywords, which
+		// the stored, redacted text may no longer show.
+		`ALTER TABLE chat_messages ADD COLUMN IF NOT EXISTS crisis BOOLEAN NOT NULL DEFAULT FALSE`,
+		`CREATE TABLE IF NOT EXISTS redaction_audit (
+			id SERIAL PRIMARY KEY,
+			session_id VARCHAR(100) NOT NULL,
+			rule VARCHAR(50) NOT NULL,
+			count INT NOT NULL,
+			created_at TIMESTAMP DEFAULT NOW()
+		)`,
+		`CREATE TABLE IF NOT EXISTS chat_exercise_state (
+			session_id VARCHAR(100) PRIMARY KEY REFERENCES chat_sessions(session_id) ON DELETE CASCADE,
+			exercise VARCHAR(50) NOT NULL,
+			step INT NOT NULL DEFAULT 0,
+			status VARCHAR(20) NOT NULL,
+			updated_at TIMESTAMP DEFAULT NOW()
+		)`,
+		`CREATE TABLE IF NOT EXISTS exercise_log (
+			id SERIAL PRIMARY KEY,
+			session_id VARCHAR(100) REFERENCES chat_sessions(session_id) ON DELETE SET NULL,
+			user_id INT REFERENCES users(id) ON DELETE SET NULL,
+			exercise VARCHAR(50) NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			created_at TIMESTAMP DEFAULT NOW()
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.pool.Exec(ctx, q); err != nil {
+			return fmt.Errorf("chat migration error: %w", err)
+		}
+	}
+	return nil
+}
+
+// CreateChatSession creates a session owned by userID, or by anonID when userID is 0.
+func (db *DB) CreateChatSession(ctx context.Context, userID int, anonID string) (*models.ChatSession, error) {
+	var s models.ChatSession
+	var owner *int
+	if userID > 0 {
+		owner = &userID
+		anonID = ""
+	}
+	err := db.pool.QueryRow(ctx,
+		`INSERT INTO chat_sessions (user_id, anon_id) VALUES ($1, NULLIF($2, '')) RETURNING id, session_id, user_id, COALESCE(anon_id, ''), lang, created_at`,
+		owner, anonID,
+	).Scan(&s.ID, &s.SessionID, &s.UserID, &s.AnonID, &s.Lang, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetOwnedChatSession returns the session only if it belongs to userID or anonID.
+// It returns nil, nil when the session does not exist or is owned by someone else.
+func (db *DB) GetOwnedChatSession(ctx context.Context, sessionID string, userID int, anonID string) (*models.ChatSession, error) {
+	var s models.ChatSession
+	err := db.pool.QueryRow(ctx,
+		`SELECT id, session_id, user_id, COALESCE(anon_id, ''), lang, created_at FROM chat_sessions
+		 WHERE session_id = $1 AND (user_id = $2 OR (anon_id IS NOT NULL AND anon_id = NULLIF($3, '')))`,
+		sessionID, userID, anonID,
+	).Scan(&s.ID, &s.SessionID, &s.UserID, &s.AnonID, &s.Lang, &s.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListChatSessions returns the caller's sessions, most recently active first.
+func (db *DB) ListChatSessions(ctx context.Context, userID int, anonID string) ([]models.ChatSession, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT s.id, s.session_id, s.user_id, COALESCE(s.anon_id, ''), s.lang, s.created_at,
+		        COUNT(m.id), MAX(m.created_at)
+		 FROM chat_sessions s
+		 LEFT JOIN chat_messages m ON m.session_id = s.session_id
+		 WHERE s.user_id = $1 OR (s.anon_id IS NOT NULL AND s.anon_id = NULLIF($2, ''))
+		 GROUP BY s.id
+		 ORDER BY COALESCE(MAX(m.created_at), s.created_at) DESC`,
+		userID, anonID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var sessions []models.ChatSession
+	for rows.Next() {
+		var s models.ChatSession
+		if err := rows.Scan(&s.ID, &s.SessionID, &s.UserID, &s.AnonID, &s.Lang, &s.CreatedAt, &s.MessageCount, &s.LastMessageAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if sessions == nil {
+		sessions = []models.ChatSession{}
+	}
+	return sessions, nil
+}
+
+func (db *DB) SetChatSessionLang(ctx context.Context, sessionID, lang string) error {
+	_, err := db.pool.Exec(ctx, `UPDATE chat_sessions SET lang = $2 WHERE session_id = $1`, sessionID, lang)
+	return err
+}
+
+// GetLastChatLang returns the language of the user's most recently active
+// chat session, or "" if they have not chatted.
+func (db *DB) GetLastChatLang(ctx context.Context, userID int) (string, error) {
+	var lang string
+	err := db.pool.QueryRow(ctx,
+		`SELECT s.lang FROM chat_sessions s
+		 LEFT JOIN chat_messages m ON m.session_id = s.session_id
+		 WHERE s.user_id = $1 AND s.lang <> ''
+		 GROUP BY s.id
+		 ORDER BY COALESCE(MAX(m.created_at), s.created_at) DESC
+		 LIMIT 1`,
+		userID,
+	).Scan(&lang)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	return lang, err
+}
+
+// DeleteChatSession removes a session and all of its messages.
+func (db *DB) DeleteChatSession(ctx context.Context, sessionID string) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("delete chat session begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM chat_messages WHERE session_id = $1`, sessionID); err != nil {
+		return fmt.Errorf("delete chat messages: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM chat_sessions WHERE session_id = $1`, sessionID); err != nil {
+		return fmt.Errorf("delete chat session: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// SaveRedactionAudit records how many values each redaction rule removed from a
+// message. The original values are never stored.
+func (db *DB) SaveRedactionAudit(ctx context.Context, sessionID string, counts map[string]int) error {
+	for rule, n := range counts {
+		if _, err := db.pool.Exec(ctx,
+			`INSERT INTO redaction_audit (session_id, rule, count) VALUES ($1, $2, $3)`,
+			sessionID, rule, n,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *DB) CountChatMessages(ctx context.Context, sessionID string) (int, error) {
+	var count int
+	err := db.pool.QueryRow(ctx, `SELECT COUNT(*) FROM chat_messages WHERE session_id = $1`, sessionID).Scan(&count)
+	return count, err
+}
+
+// GetExerciseState returns the session's pending or active exercise, or nil.
+func (db *DB) GetExerciseState(ctx context.Context, sessionID string) (*models.ExerciseState, error) {
+	var st models.ExerciseState
+	err := db.pool.QueryRow(ctx,
+		`SELECT session_id, exercise, step, status, updated_at FROM chat_exercise_state WHERE session_id = $1`,
+		sessionID,
+	).Scan(&st.SessionID, &st.Exercise, &st.Step, &st.Status, &st.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &st, nil
+}
+
+func (db *DB) SaveExerciseState(ctx context.Context, st *models.ExerciseState) error {
+	_, err := db.pool.Exec(ctx,
+		`INSERT INTO chat_exercise_state (session_id, exercise, step, status, updated_at) VALUES ($1, $2, $3, $4, NOW())
+		 ON CONFLICT (session_id) DO UPDATE SET exercise = EXCLUDED.exercise, step = EXCLUDED.step, status = EXCLUDED.status, updated_at = NOW()`,
+		st.SessionID, st.Exercise, st.Step, st.Status,
+	)
+	return err
+}
+
+// LogExercise records that an exercise was completed or cancelled. userID 0 means a guest.
+func (db *DB) LogExercise(ctx context.Context, sessionID string, userID int, exercise, status string) error {
+	var uid *int
+	if userID > 0 {
+		uid = &userID
+	}
+	_, err := db.pool.Exec(ctx,
+		`INSERT INTO exercise_log (session_id, user_id, exercise, status) VALUES ($1, $2, $3, $4)`,
+		sessionID, uid, exercise, status,
+	)
+	return err
+}
+
+// GetSessionTranscript returns every message of a session, oldest first.
+func (db *DB) GetSessionTranscript(ctx context.Context, sessionID string) ([]models.ChatMessage, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT id, session_id, role, content, crisis, created_at FROM chat_messages WHERE session_id = $1 ORDER BY created_at ASC, id ASC`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var msgs []models.ChatMessage
+	for rows.Next() {
+		var m models.ChatMessage
+		if err := rows.Scan(&m.ID, &m.SessionID, &m.Role, &m.Content, &m.Crisis, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if msgs == nil {
+		msgs = []models.ChatMessage{}
+	}
+	return msgs, nil
+}
+
+func (db *DB) GetExerciseLog(ctx context.Context, sessionID string) ([]models.ExerciseLogEntry, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT exercise, status, created_at FROM exercise_log WHERE session_id = $1 ORDER BY created_at ASC`,
+		sessionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []models.ExerciseLogEntry
+	for rows.Next() {
+		var e models.ExerciseLogEntry
+		if err := rows.Scan(&e.Exercise, &e.Status, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []models.ExerciseLogEntry{}
+	}
+	return entries, nil
+}
@@ -0,0 +1,538 @@
+package repository
+
+import (
+	"context"
+	"edu-web-backend/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrSlotTaken is returned when a booking overlaps another booked appointment
+// of the counselor or the student.
+var ErrSlotTaken = errors.New("slot already booked")
+
+// MigrateCounseling creates the tables behind counselor-facing features.
+func (db *DB) MigrateCounseling(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS anonymous_questions (
+			id SERIAL PRIMARY KEY,
+			question TEXT NOT NULL,
+			token_hash VARCHAR(64) UNIQUE NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'open',
+			urgent BOOLEAN NOT NULL DEFAULT FALSE,
+			claimed_by INT REFERENCES users(id) ON DELETE SET NULL,
+			answer TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT NOW(),
+			claimed_at TIMESTAMP,
+			answered_at TIMESTAMP,
+			closed_at TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_anonymous_questions_status ON anonymous_questions(status, created_at)`,
+		`CREATE TABLE IF NOT EXISTS counselor_availability (
+			id SERIAL PRIMARY KEY,
+			counselor_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+			start_time TIME NOT NULL,
+			end_time TIME NOT NULL,
+			slot_minutes INT NOT NULL DEFAULT 30 CHECK (slot_minutes > 0),
+			CHECK (end_time > start_time)
+		)`,
+		`CREATE TABLE IF NOT EXISTS availability_exceptions (
+			id SERIAL PRIMARY KEY,
+			counselor_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			date DATE NOT NULL,
+			start_time TIME,
+			end_time TIME,
+			reason TEXT NOT NULL DEFAULT ''
+		)`,
+		`CREATE TABLE IF NOT EXISTS appointments (
+			id SERIAL PRIMARY KEY,
+			counselor_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			student_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			starts_at TIMESTAMP NOT NULL,
+			ends_at TIMESTAMP NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'booked',
+			note TEXT NOT NULL DEFAULT '',
+			reminder_sent_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT NOW(),
+			updated_at TIMESTAMP DEFAULT NOW(),
+			CHECK (ends_at > starts_at)
+		)`,
+		// Backstop for the overlap check in BookAppointment: one booking per counselor per start time.
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_appointments_counselor_slot ON appointments(counselor_id, starts_at) WHERE status = 'booked'`,
+		`CREATE INDEX IF NOT EXISTS idx_appointments_student ON appointments(student_id, starts_at)`,
+		// A summary is a snapshot that outlives its chat session, but not the
+		// direct message carrying it: DM retention deletes it with the message
+		// in delete mode and on its own in anonymize mode.
+		`CREATE TABLE IF NOT EXISTS chat_summaries (
+			id SERIAL PRIMARY KEY,
+			session_id VARCHAR(100) NOT NULL,
+			student_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			counselor_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			message_id INT NOT NULL REFERENCES direct_messages(id) ON DELETE CASCADE,
+			summary JSONB NOT NULL,
+			created_at TIMESTAMP DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_chat_summaries_counselor ON chat_summaries(counselor_id, created_at DESC)`,
+		`CREATE INDEX IF NOT EXISTS idx_chat_summaries_message ON chat_summaries(message_id)`,
+		// Summaries whose message was anonymized before retention covered them.
+		`DELETE FROM chat_summaries s USING direct_messages m WHERE m.id = s.message_id AND m.content = '` + AnonymizedContent + `'`,
+	}
+	for _, q := range queries {
+		if _, err := db.pool.Exec(ctx, q); err != nil {
+			return fmt.Errorf("counseling migration error: %w", err)
+		}
+	}
+	return nil
+}
+
+const questionColumns = `id, question, status, urgent, claimed_by, answer, created_at, answered_at`
+
+func scanQuestion(row pgx.Row) (*models.AnonymousQuestion, error) {
+	var q models.AnonymousQuestion
+	err := row.Scan(&q.ID, &q.Question, &q.Status, &q.Urgent, &q.ClaimedBy, &q.Answer, &q.CreatedAt, &q.AnsweredAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &q, nil
+}
+
+func (db *DB) CreateAnonymousQuestion(ctx context.Context, question, tokenHash string, urgent bool) (*models.AnonymousQuestion, error) {
+	return scanQuestion(db.pool.QueryRow(ctx,
+		`INSERT INTO anonymous_questions (question, token_hash, urgent) VALUES ($1, $2, $3) RETURNING `+questionColumns,
+		question, tokenHash, urgent,
+	))
+}
+
+func (db *DB) GetAnonymousQuestionByToken(ctx context.Context, tokenHash string) (*models.AnonymousQuestion, error) {
+	return scanQuestion(db.pool.QueryRow(ctx,
+		`SELECT `+questionColumns+` FROM anonymous_questions WHERE token_hash = $1`,
+		tokenHash,
+	))
+}
+
+// ListAnonymousQuestions returns the counselor queue, urgent questions first.
+// An empty status lists every question that is not closed.
+func (db *DB) ListAnonymousQuestions(ctx context.Context, status string) ([]models.AnonymousQuestion, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+questionColumns+` FROM anonymous_questions
+		 WHERE ($1 = '' AND status <> 'closed') OR status = $1
+		 ORDER BY urgent DESC, created_at ASC LIMIT 200`,
+		status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var questions []models.AnonymousQuestion
+	for rows.Next() {
+		q, err := scanQuestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		questions = append(questions, *q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if questions == nil {
+		questions = []models.AnonymousQuestion{}
+	}
+	return questions, nil
+}
+
+// ClaimAnonymousQuestion assigns an open question to a counselor. It returns
+// nil if the question does not exist or was already claimed.
+func (db *DB) ClaimAnonymousQuestion(ctx context.Context, id, counselorID int) (*models.AnonymousQuestion, error) {
+	return scanQuestion(db.pool.QueryRow(ctx,
+		`UPDATE anonymous_questions SET status = 'claimed', claimed_by = $2, claimed_at = NOW()
+		 WHERE id = $1 AND status = 'open' RETURNING `+questionColumns,
+		id, counselorID,
+	))
+}
+
+// AnswerAnonymousQuestion saves the reply of the counselor who claimed the
+// question. Answering again before it is closed replaces the reply.
+func (db *DB) AnswerAnonymousQuestion(ctx context.Context, id, counselorID int, answer string) (*models.AnonymousQuestion, error) {
+	return scanQuestion(db.pool.QueryRow(ctx,
+		`UPDATE anonymous_questions SET status = 'answered', answer = $3, answered_at = NOW()
+		 WHERE id = $1 AND claimed_by = $2 AND status IN ('claimed', 'answered') RETURNING `+questionColumns,
+		id, counselorID, answer,
+	))
+}
+
+// CloseAnonymousQuestion closes a question that is open or belongs to the counselor.
+func (db *DB) CloseAnonymousQuestion(ctx context.Context, id, counselorID int) (*models.AnonymousQuestion, error) {
+	return scanQuestion(db.pool.QueryRow(ctx,
+		`UPDATE anonymous_questions SET status = 'closed', closed_at = NOW()
+		 WHERE id = $1 AND status <> 'closed' AND (status = 'open' OR claimed_by = $2) RETURNING `+questionColumns,
+		id, counselorID,
+	))
+}
+
+func (db *DB) ListCounselors(ctx context.Context) ([]models.User, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT id, username, display_name, role, created_at FROM users WHERE role = $1 ORDER BY display_name ASC`,
+		models.RoleCounselor,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.DisplayName, &u.Role, &u.CreatedAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if users == nil {
+		users = []models.User{}
+	}
+	return users, nil
+}
+
+func (db *DB) GetAvailability(ctx context.Context, counselorID int) ([]models.Availability, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT id, counselor_id, weekday, to_char(start_time, 'HH24:MI'), to_char(end_time, 'HH24:MI'), slot_minutes
+		 FROM counselor_availability WHERE counselor_id = $1 ORDER BY weekday, start_time`,
+		counselorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var slots []models.Availability
+	for rows.Next() {
+		var a models.Availability
+		if err := rows.Scan(&a.ID, &a.CounselorID, &a.Weekday, &a.StartTime, &a.EndTime, &a.SlotMinutes); err != nil {
+			return nil, err
+		}
+		slots = append(slots, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if slots == nil {
+		slots = []models.Availability{}
+	}
+	return slots, nil
+}
+
+// ReplaceAvailability swaps the counselor's weekly schedule in one transaction.
+func (db *DB) ReplaceAvailability(ctx context.Context, counselorID int, slots []models.Availability) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("replace availability begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM counselor_availability WHERE counselor_id = $1`, counselorID); err != nil {
+		return fmt.Errorf("replace availability delete: %w", err)
+	}
+	for _, a := range slots {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO counselor_availability (counselor_id, weekday, start_time, end_time, slot_minutes) VALUES ($1, $2, $3::time, $4::time, $5)`,
+			counselorID, a.Weekday, a.StartTime, a.EndTime, a.SlotMinutes,
+		); err != nil {
+			return fmt.Errorf("replace availability insert: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// GetAvailabilityExceptions returns exceptions between two dates (YYYY-MM-DD, inclusive).
+func (db *DB) GetAvailabilityExceptions(ctx context.Context, counselorID int, from, to string) ([]models.AvailabilityException, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT id, counselor_id, to_char(date, 'YYYY-MM-DD'), COALESCE(to_char(start_time, 'HH24:MI'), ''), COALESCE(to_char(end_time, 'HH24:MI'), ''), reason
+		 FROM availability_exceptions WHERE counselor_id = $1 AND date BETWEEN $2::date AND $3::date ORDER BY date, start_time`,
+		counselorID, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var exceptions []models.AvailabilityException
+	for rows.Next() {
+		var e models.AvailabilityException
+		if err := rows.Scan(&e.ID, &e.CounselorID, &e.Date, &e.StartTime, &e.EndTime, &e.Reason); err != nil {
+			return nil, err
+		}
+		exceptions = append(exceptions, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if exceptions == nil {
+		exceptions = []models.AvailabilityException{}
+	}
+	return exceptions, nil
+}
+
+func (db *DB) CreateAvailabilityException(ctx context.Context, e *models.AvailabilityException) error {
+	return db.pool.QueryRow(ctx,
+		`INSERT INTO availability_exceptions (counselor_id, date, start_time, end_time, reason)
+		 VALUES ($1, $2::date, NULLIF($3, '')::time, NULLIF($4, '')::time, $5) RETURNING id`,
+		e.CounselorID, e.Date, e.StartTime, e.EndTime, e.Reason,
+	).Scan(&e.ID)
+}
+
+func (db *DB) DeleteAvailabilityException(ctx context.Context, id, counselorID int) (bool, error) {
+	tag, err := db.pool.Exec(ctx, `DELETE FROM availability_exceptions WHERE id = $1 AND counselor_id = $2`, id, counselorID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+const appointmentColumns = `id, counselor_id, student_id, starts_at, ends_at, status, note, created_at`
+
+func scanAppointment(row pgx.Row) (*models.Appointment, error) {
+	var a models.Appointment
+	err := row.Scan(&a.ID, &a.CounselorID, &a.StudentID, &a.StartsAt, &a.EndsAt, &a.Status, &a.Note, &a.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &a, nil
+}
+
+func scanAppointments(rows pgx.Rows) ([]models.Appointment, error) {
+	defer rows.Close()
+	var appts []models.Appointment
+	for rows.Next() {
+		a, err := scanAppointment(rows)
+		if err != nil {
+			return nil, err
+		}
+		appts = append(appts, *a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if appts == nil {
+		appts = []models.Appointment{}
+	}
+	return appts, nil
+}
+
+func (db *DB) GetAppointment(ctx context.Context, id int) (*models.Appointment, error) {
+	return scanAppointment(db.pool.QueryRow(ctx, `SELECT `+appointmentColumns+` FROM appointments WHERE id = $1`, id))
+}
+
+// GetBookedAppointments returns the counselor's booked appointments overlapping [from, to).
+func (db *DB) GetBookedAppointments(ctx context.Context, counselorID int, from, to time.Time) ([]models.Appointment, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+appointmentColumns+` FROM appointments
+		 WHERE counselor_id = $1 AND status = 'booked' AND starts_at < $3 AND ends_at > $2 ORDER BY starts_at`,
+		counselorID, from.UTC(), to.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAppointments(rows)
+}
+
+// ListAppointments returns appointments where the user is the student or the
+// counselor. With upcoming set, only booked appointments that have not ended.
+func (db *DB) ListAppointments(ctx context.Context, userID int, upcoming bool) ([]models.Appointment, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+appointmentColumns+` FROM appointments
+		 WHERE (student_id = $1 OR counselor_id = $1) AND (NOT $2 OR (status = 'booked' AND ends_at > $3))
+		 ORDER BY starts_at`,
+		userID, upcoming, time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAppointments(rows)
+}
+
+// lockAndCheckOverlap serializes bookings per counselor and per student by
+// locking both user rows, then fails with ErrSlotTaken if the counselor or the
+// student already has a booked appointment overlapping the range. excludeID
+// skips the appointment being rescheduled. Rows are locked in id order so two
+// bookings between the same pair cannot deadlock.
+func lockAndCheckOverlap(ctx context.Context, tx pgx.Tx, a *models.Appointment, excludeID int) error {
+	if _, err := tx.Exec(ctx, `SELECT id FROM users WHERE id IN ($1, $2) ORDER BY id FOR UPDATE`, a.CounselorID, a.StudentID); err != nil {
+		return fmt.Errorf("lock counselor and student: %w", err)
+	}
+	var taken bool
+	err := tx.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM appointments
+		 WHERE status = 'booked' AND id <> $1 AND (counselor_id = $2 OR student_id = $3)
+		 AND starts_at < $5 AND ends_at > $4)`,
+		excludeID, a.CounselorID, a.StudentID, a.StartsAt.UTC(), a.EndsAt.UTC(),
+	).Scan(&taken)
+	if err != nil {
+		return fmt.Errorf("check overlap: %w", err)
+	}
+	if taken {
+		return ErrSlotTaken
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// BookAppointment inserts a booked appointment unless it overlaps another one.
+func (db *DB) BookAppointment(ctx context.Context, a *models.Appointment) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("book appointment begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockAndCheckOverlap(ctx, tx, a, 0); err != nil {
+		return err
+	}
+	booked, err := scanAppointment(tx.QueryRow(ctx,
+		`INSERT INTO appointments (counselor_id, student_id, starts_at, ends_at, note) VALUES ($1, $2, $3, $4, $5) RETURNING `+appointmentColumns,
+		a.CounselorID, a.StudentID, a.StartsAt.UTC(), a.EndsAt.UTC(), a.Note,
+	))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrSlotTaken
+		}
+		return fmt.Errorf("book appointment: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		if isUniqueViolation(err) {
+			return ErrSlotTaken
+		}
+		return fmt.Errorf("book appointment commit: %w", err)
+	}
+	*a = *booked
+	return nil
+}
+
+// RescheduleAppointment moves a booked appointment to a new time range and
+// re-arms its reminder.
+func (db *DB) RescheduleAppointment(ctx context.Context, a *models.Appointment, startsAt, endsAt time.Time) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("reschedule appointment begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	moved := *a
+	moved.StartsAt, moved.EndsAt = startsAt, endsAt
+	if err := lockAndCheckOverlap(ctx, tx, &moved, a.ID); err != nil {
+		return err
+	}
+	updated, err := scanAppointment(tx.QueryRow(ctx,
+		`UPDATE appointments SET starts_at = $2, ends_at = $3, reminder_sent_at = NULL, updated_at = NOW()
+		 WHERE id = $1 AND status = 'booked' RETURNING `+appointmentColumns,
+		a.ID, startsAt.UTC(), endsAt.UTC(),
+	))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrSlotTaken
+		}
+		return fmt.Errorf("reschedule appointment: %w", err)
+	}
+	if updated == nil {
+		return fmt.Errorf("appointment %d is not booked", a.ID)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("reschedule appointment commit: %w", err)
+	}
+	*a = *updated
+	return nil
+}
+
+// CancelAppointment cancels a booked appointment. It returns nil if the
+// appointment is not currently booked.
+func (db *DB) CancelAppointment(ctx context.Context, id int) (*models.Appointment, error) {
+	return scanAppointment(db.pool.QueryRow(ctx,
+		`UPDATE appointments SET status = 'cancelled', updated_at = NOW() WHERE id = $1 AND status = 'booked' RETURNING `+appointmentColumns,
+		id,
+	))
+}
+
+// GetDueReminders returns booked appointments starting before the given time
+// whose reminder has not been sent yet.
+func (db *DB) GetDueReminders(ctx context.Context, before time.Time) ([]models.Appointment, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+appointmentColumns+` FROM appointments
+		 WHERE status = 'booked' AND reminder_sent_at IS NULL AND starts_at > $1 AND starts_at <= $2 ORDER BY starts_at`,
+		time.Now().UTC(), before.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanAppointments(rows)
+}
+
+func (db *DB) MarkReminderSent(ctx context.Context, id int) error {
+	_, err := db.pool.Exec(ctx, `UPDATE appointments SET reminder_sent_at = NOW() WHERE id = $1`, id)
+	return err
+}
+
+// ShareChatSummary sends the summary text as a direct message from the student
+// to the counselor and stores the structured summary with it, in one transaction.
+func (db *DB) ShareChatSummary(ctx context.Context, share *models.ChatSummaryShare, text string) error {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("share summary begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO direct_messages (sender_id, receiver_id, content) VALUES ($1, $2, $3) RETURNING id`,
+		share.StudentID, share.CounselorID, text,
+	).Scan(&share.MessageID); err != nil {
+		return fmt.Errorf("share summary message: %w", err)
+	}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO chat_summaries (session_id, student_id, counselor_id, message_id, summary) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at`,
+		share.SessionID, share.StudentID, share.CounselorID, share.MessageID, share.Summary,
+	).Scan(&share.ID, &share.CreatedAt); err != nil {
+		return fmt.Errorf("share summary: %w", err)
+	}
+	return tx.Commit(ctx)
+}
+
+// GetChatSummaryShares returns the summaries students shared with a counselor, newest first.
+func (db *DB) GetChatSummaryShares(ctx context.Context, counselorID int) ([]models.ChatSummaryShare, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT id, session_id, student_id, counselor_id, message_id, summary, created_at
+		 FROM chat_summaries WHERE counselor_id = $1 ORDER BY created_at DESC`,
+		counselorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var shares []models.ChatSummaryShare
+	for rows.Next() {
+		var s models.ChatSummaryShare
+		if err := rows.Scan(&s.ID, &s.SessionID, &s.StudentID, &s.CounselorID, &s.MessageID, &s.Summary, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		shares = append(shares, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if shares == nil {
+		shares = []models.ChatSummaryShare{}
+	}
+	return shares, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"edu-web-backend/internal/models"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrMediaInUse is returned when deleting a file that a video or audio plays.
+var ErrMediaInUse = errors.New("media file is used by a video or audio")
+
+// MigrateMedia creates the media library and links videos and audios to it.
+// It runs after Migrate and MigrateAuth.
+func (db *DB) MigrateMedia(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS media_files (
+			id SERIAL PRIMARY KEY,
+			storage_key VARCHAR(100) NOT NULL UNIQUE,
+			filename VARCHAR(255) NOT NULL,
+			content_type VARCHAR(100) NOT NULL,
+			kind VARCHAR(10) NOT NULL,
+			size_bytes BIGINT NOT NULL,
+			sha256 CHAR(64) NOT NULL,
+			uploaded_by INT REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP DEFAULT NOW()
+		)`,
+		`ALTER TABLE videos ADD COLUMN IF NOT EXISTS media_id INT REFERENCES media_files(id)`,
+		`ALTER TABLE audios ADD COLUMN IF NOT EXISTS media_id INT REFERENCES media_files(id)`,
+		`ALTER TABLE media_files ADD COLUMN IF NOT EXISTS duration_seconds INT`,
+		`ALTER TABLE media_files ADD COLUMN IF NOT EXISTS bitrate INT`,
+		`ALTER TABLE media_files ADD COLUMN IF NOT EXISTS codec VARCHAR(50) NOT NULL DEFAULT ''`,
+		`ALTER TABLE media_files ADD COLUMN IF NOT EXISTS width INT`,
+		`ALTER TABLE media_files ADD COLUMN IF NOT EXISTS height INT`,
+	}
+	for _, q := range queries {
+		if _, err := db.pool.Exec(ctx, q); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+	return nil
+}
+
+const mediaFileColumns = `id, storage_key, filename, content_type, kind, size_bytes, sha256,
+	duration_seconds, bitrate, codec, width, height, uploaded_by, created_at`
+
+func scanMediaFile(row pgx.Row) (*models.MediaFile, error) {
+	var m models.MediaFile
+	if err := row.Scan(&m.ID, &m.StorageKey, &m.Filename, &m.ContentType, &m.Kind, &m.SizeBytes, &m.SHA256,
+		&m.DurationSeconds, &m.Bitrate, &m.Codec, &m.Width, &m.Height, &m.UploadedBy, &m.CreatedAt); err != nil {
+		return nil, err
+	}
+	m.Duration = formatDuration(m.DurationSeconds)
+	return &m, nil
+}
+
+func (db *DB) CreateMediaFile(ctx context.Context, m *models.MediaFile) error {
+	return db.pool.QueryRow(ctx,
+		`INSERT INTO media_files (storage_key, filename, content_type, kind, size_bytes, sha256,
+			duration_seconds, bitrate, codec, width, height, uploaded_by)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12) RETURNING id, created_at`,
+		m.StorageKey, m.Filename, m.ContentType, m.Kind, m.SizeBytes, m.SHA256,
+		m.DurationSeconds, m.Bitrate, m.Codec, m.Width, m.Height, m.UploadedBy,
+	).Scan(&m.ID, &m.CreatedAt)
+}
+
+func (db *DB) GetMediaFile(ctx context.Context, id int) (*models.MediaFile, error) {
+	m, err := scanMediaFile(db.pool.QueryRow(ctx,
+		`SELECT `+mediaFileColumns+` FROM media_files WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return m, err
+}
+
+// ListMediaFiles returns the library newest first.
+func (db *DB) ListMediaFiles(ctx context.Context) ([]models.MediaFile, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+mediaFileColumns+` FROM media_files ORDER BY created_at DESC, id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var files []models.MediaFile
+	for rows.Next() {
+		m, err := scanMediaFile(rows)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, *m)
+	}
+	return files, rows.Err()
+}
+
+// DeleteMediaFile removes a library row. The foreign keys from videos and
+// audios refuse the delete while the file is in use.
+func (db *DB) DeleteMediaFile(ctx context.Context, id int) error {
+	_, err := db.pool.Exec(ctx, `DELETE FROM media_files WHERE id = $1`, id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return ErrMediaInUse
+	}
+	return err
+}
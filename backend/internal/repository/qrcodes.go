@@ -0,0 +1,465 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"edu-web-backend/internal/models"
+	"edu-web-backend/internal/qr"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// slugAlphabet leaves out characters that are easy to misread on a poster.
+const (
+	slugAlphabet = "abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	slugLength   = 8
+)
+
+// MigrateQRCodes adds short-link slugs, scan events and logos to QR codes.
+// It runs after Migrate and MigrateAuth, which create qrcodes and users.
+func (db *DB) MigrateQRCodes(ctx context.Context) error {
+	queries := []string{
+		// QR images are rendered on request now; drop the stored PNG data URIs.
+		`ALTER TABLE qrcodes DROP COLUMN IF EXISTS qr_data`,
+		`ALTER TABLE qrcodes ADD COLUMN IF NOT EXISTS slug VARCHAR(32) UNIQUE`,
+		`ALTER TABLE qrcodes ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT NOW()`,
+		// Structured fields of Wi-Fi, contact, event, geo and SMS codes; NULL for links.
+		`ALTER TABLE qrcodes ADD COLUMN IF NOT EXISTS payload JSONB`,
+		// Slugs drawn from slugAlphabet like newSlug's; the correlated WHERE
+		// makes Postgres draw a new one for every row.
+		fmt.Sprintf(`UPDATE qrcodes SET slug = (
+			SELECT string_agg(substr('%s', 1 + floor(random() * %d)::int, 1), '')
+			FROM generate_series(1, %d) WHERE qrcodes.id IS NOT NULL
+		) WHERE slug IS NULL`, slugAlphabet, len(slugAlphabet), slugLength),
+		`ALTER TABLE qrcodes ALTER COLUMN slug SET NOT NULL`,
+		// Codes made before ownership stay public with no owner; only admins manage them.
+		`ALTER TABLE qrcodes ADD COLUMN IF NOT EXISTS created_by INT REFERENCES users(id) ON DELETE SET NULL`,
+		`ALTER TABLE qrcodes ADD COLUMN IF NOT EXISTS visibility VARCHAR(10) NOT NULL DEFAULT 'public'`,
+		`ALTER TABLE qrcodes ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP`,
+		`CREATE INDEX IF NOT EXISTS idx_qrcodes_created_by ON qrcodes(created_by)`,
+		// SHA-256 of the text a decoded image must contain to match the code:
+		// the target of a link code, the encoded fields of a payload code.
+		`ALTER TABLE qrcodes ADD COLUMN IF NOT EXISTS content_hash CHAR(64)`,
+		`CREATE INDEX IF NOT EXISTS idx_qrcodes_content_hash ON qrcodes(content_hash)`,
+		`CREATE TABLE IF NOT EXISTS qr_scans (
+			id BIGSERIAL PRIMARY KEY,
+			qrcode_id INT NOT NULL REFERENCES qrcodes(id) ON DELETE CASCADE,
+			scanned_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			user_agent VARCHAR(300) NOT NULL DEFAULT '',
+			referer VARCHAR(500) NOT NULL DEFAULT '',
+			device VARCHAR(20) NOT NULL DEFAULT 'other'
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_qr_scans_qrcode ON qr_scans(qrcode_id, scanned_at)`,
+		`CREATE TABLE IF NOT EXISTS qr_logos (
+			id SERIAL PRIMARY KEY,
+			name VARCHAR(100) NOT NULL,
+			content_type VARCHAR(50) NOT NULL,
+			data BYTEA NOT NULL,
+			uploaded_by INT REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP DEFAULT NOW()
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.pool.Exec(ctx, q); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+	return db.backfillQRContentHashes(ctx)
+}
+
+// backfillQRContentHashes hashes codes stored before content_hash existed
+// and rehashes payload codes, whose encoding can change between releases
+// (events gained UID and DTSTAMP). Payloads are encoded in Go, so this cannot
+// be a plain UPDATE.
+func (db *DB) backfillQRContentHashes(ctx context.Context) error {
+	rows, err := db.pool.Query(ctx, `SELECT `+qrCodeColumns+` FROM qrcodes WHERE content_hash IS NULL OR payload IS NOT NULL`)
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	var codes []models.QRCode
+	for rows.Next() {
+		q, err := scanQRCode(rows)
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("migration failed: %w", err)
+		}
+		codes = append(codes, *q)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	for i := range codes {
+		if _, err := db.pool.Exec(ctx, `UPDATE qrcodes SET content_hash = $2 WHERE id = $1 AND content_hash IS DISTINCT FROM $2`,
+			codes[i].ID, qrContentHash(&codes[i])); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// qrContentHash is the content_hash of a code. A payload that no longer
+// parses hashes as empty text, which no decoded image matches.
+func qrContentHash(code *models.QRCode) string {
+	text := code.TargetURL
+	if code.Payload != nil {
+		text = ""
+		if p, err := qr.ParsePayload(code.Type, code.Payload); err == nil {
+			text = p.Text()
+		}
+	}
+	return contentHash(text)
+}
+
+func contentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// utcPtr converts an optional time for a TIMESTAMP column.
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+func newSlug() (string, error) {
+	b := make([]byte, slugLength)
+	max := big.NewInt(int64(len(slugAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = slugAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
+
+const qrCodeColumns = `id, label, target_url, type, payload, slug, created_by, visibility, expires_at, created_at, updated_at`
+
+func scanQRCode(row pgx.Row) (*models.QRCode, error) {
+	var q models.QRCode
+	if err := row.Scan(&q.ID, &q.Label, &q.TargetURL, &q.Type, &q.Payload, &q.Slug, &q.CreatedBy, &q.Visibility, &q.ExpiresAt, &q.CreatedAt, &q.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &q, nil
+}
+
+// GetAllQRCodes lists the public codes that have not expired plus every code
+// of viewerID (0 for guests), or all codes when all is set.
+func (db *DB) GetAllQRCodes(ctx context.Context, viewerID int, all bool) ([]models.QRCode, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+qrCodeColumns+` FROM qrcodes
+		 WHERE $1 OR created_by = $2 OR (visibility = 'public' AND (expires_at IS NULL OR expires_at > $3))
+		 ORDER BY id ASC`,
+		all, viewerID, time.Now().UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var qrs []models.QRCode
+	for rows.Next() {
+		q, err := scanQRCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		qrs = append(qrs, *q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return qrs, nil
+}
+
+func (db *DB) GetQRCode(ctx context.Context, id int) (*models.QRCode, error) {
+	q, err := scanQRCode(db.pool.QueryRow(ctx, `SELECT `+qrCodeColumns+` FROM qrcodes WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return q, err
+}
+
+func (db *DB) GetQRCodeBySlug(ctx context.Context, slug string) (*models.QRCode, error) {
+	q, err := scanQRCode(db.pool.QueryRow(ctx, `SELECT `+qrCodeColumns+` FROM qrcodes WHERE slug = $1`, slug))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return q, err
+}
+
+// FindQRCodesByContent returns link codes whose target is text and payload
+// codes that encode to text, looked up by content_hash.
+func (db *DB) FindQRCodesByContent(ctx context.Context, text string) ([]models.QRCode, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+qrCodeColumns+` FROM qrcodes WHERE content_hash = $1 ORDER BY id ASC`, contentHash(text))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var qrs []models.QRCode
+	for rows.Next() {
+		q, err := scanQRCode(rows)
+		if err != nil {
+			return nil, err
+		}
+		qrs = append(qrs, *q)
+	}
+	return qrs, rows.Err()
+}
+
+// SaveQRCode stores a code under a fresh random slug, retrying on the rare
+// collision. Link codes have a TargetURL, payload codes an empty one and a
+// Payload.
+func (db *DB) SaveQRCode(ctx context.Context, code *models.QRCode) (*models.QRCode, error) {
+	for attempt := 0; ; attempt++ {
+		slug, err := newSlug()
+		if err != nil {
+			return nil, err
+		}
+		q, err := scanQRCode(db.pool.QueryRow(ctx,
+			`INSERT INTO qrcodes (label, target_url, type, payload, slug, created_by, visibility, expires_at, content_hash)
+			 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9) RETURNING `+qrCodeColumns,
+			code.Label, code.TargetURL, code.Type, code.Payload, slug, code.CreatedBy, code.Visibility, utcPtr(code.ExpiresAt), qrContentHash(code),
+		))
+		if isUniqueViolation(err) && attempt < 3 {
+			continue
+		}
+		return q, err
+	}
+}
+
+// SaveQRCodes stores link codes in one transaction: either all rows are
+// created or none. Slug collisions are retried without aborting the
+// transaction.
+func (db *DB) SaveQRCodes(ctx context.Context, codes []models.QRCode) ([]models.QRCode, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	saved := make([]models.QRCode, 0, len(codes))
+	for _, c := range codes {
+		var q *models.QRCode
+		for attempt := 0; q == nil; attempt++ {
+			if attempt > 3 {
+				return nil, fmt.Errorf("could not find a free slug for %q", c.Label)
+			}
+			slug, err := newSlug()
+			if err != nil {
+				return nil, err
+			}
+			q, err = scanQRCode(tx.QueryRow(ctx,
+				`INSERT INTO qrcodes (label, target_url, type, slug, created_by, visibility, expires_at, content_hash)
+				 VALUES ($1,$2,$3,$4,$5,$6,$7,$8)
+				 ON CONFLICT (slug) DO NOTHING RETURNING `+qrCodeColumns,
+				c.Label, c.TargetURL, c.Type, slug, c.CreatedBy, c.Visibility, utcPtr(c.ExpiresAt), contentHash(c.TargetURL),
+			))
+			if errors.Is(err, pgx.ErrNoRows) {
+				q = nil
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		saved = append(saved, *q)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// UpdateQRCodeDetails sets the label, visibility and expiry of a code; a
+// nil expiresAt makes it permanent. It returns nil when the code does not
+// exist.
+func (db *DB) UpdateQRCodeDetails(ctx context.Context, id int, label, visibility string, expiresAt *time.Time) (*models.QRCode, error) {
+	q, err := scanQRCode(db.pool.QueryRow(ctx,
+		`UPDATE qrcodes SET label = $2, visibility = $3, expires_at = $4, updated_at = NOW()
+		 WHERE id = $1 RETURNING `+qrCodeColumns,
+		id, label, visibility, utcPtr(expiresAt),
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return q, err
+}
+
+// DeleteQRCode removes a code with its scans. It reports whether the code
+// existed.
+func (db *DB) DeleteQRCode(ctx context.Context, id int) (bool, error) {
+	tag, err := db.pool.Exec(ctx, `DELETE FROM qrcodes WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// UpdateQRCodeTarget changes where a printed code redirects to. It returns
+// nil when the code does not exist.
+func (db *DB) UpdateQRCodeTarget(ctx context.Context, id int, targetURL string) (*models.QRCode, error) {
+	q, err := scanQRCode(db.pool.QueryRow(ctx,
+		`UPDATE qrcodes SET target_url = $2, content_hash = $3, updated_at = NOW() WHERE id = $1 RETURNING `+qrCodeColumns,
+		id, targetURL, contentHash(targetURL),
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return q, err
+}
+
+// UpdateQRCodePayload replaces the structured fields of a payload code of
+// type kind. It returns nil when the code does not exist.
+func (db *DB) UpdateQRCodePayload(ctx context.Context, id int, kind string, payload json.RawMessage) (*models.QRCode, error) {
+	hash := qrContentHash(&models.QRCode{Type: kind, Payload: payload})
+	q, err := scanQRCode(db.pool.QueryRow(ctx,
+		`UPDATE qrcodes SET payload = $2, content_hash = $3, updated_at = NOW() WHERE id = $1 RETURNING `+qrCodeColumns,
+		id, payload, hash,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return q, err
+}
+
+func (db *DB) RecordQRScan(ctx context.Context, scan models.QRScan) error {
+	_, err := db.pool.Exec(ctx,
+		`INSERT INTO qr_scans (qrcode_id, user_agent, referer, device) VALUES ($1,$2,$3,$4)`,
+		scan.QRCodeID, scan.UserAgent, scan.Referer, scan.Device,
+	)
+	return err
+}
+
+// GetQRScanStats aggregates scans of one code since the given time. Days are
+// counted in loc so a morning of poster scans is not split at UTC midnight.
+func (db *DB) GetQRScanStats(ctx context.Context, qrcodeID int, since time.Time, loc *time.Location) (*models.QRScanStats, error) {
+	stats := &models.QRScanStats{
+		QRCodeID: qrcodeID,
+		Daily:    []models.QRScanDay{},
+		Devices:  map[string]int{},
+		Referers: []models.QRReferer{},
+	}
+	since = since.UTC()
+
+	err := db.pool.QueryRow(ctx,
+		`SELECT COUNT(*), MAX(scanned_at) FROM qr_scans WHERE qrcode_id = $1 AND scanned_at >= $2`,
+		qrcodeID, since,
+	).Scan(&stats.Total, &stats.LastScanAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.pool.Query(ctx,
+		`SELECT (scanned_at AT TIME ZONE 'UTC' AT TIME ZONE $3)::date AS day, COUNT(*)
+		 FROM qr_scans WHERE qrcode_id = $1 AND scanned_at >= $2
+		 GROUP BY day ORDER BY day`,
+		qrcodeID, since, loc.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var d models.QRScanDay
+		if err := rows.Scan(&d.Day, &d.Count); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stats.Daily = append(stats.Daily, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.pool.Query(ctx,
+		`SELECT device, COUNT(*) FROM qr_scans WHERE qrcode_id = $1 AND scanned_at >= $2 GROUP BY device`,
+		qrcodeID, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var device string
+		var n int
+		if err := rows.Scan(&device, &n); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		stats.Devices[device] = n
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.pool.Query(ctx,
+		`SELECT referer, COUNT(*) AS n FROM qr_scans
+		 WHERE qrcode_id = $1 AND scanned_at >= $2 AND referer <> ''
+		 GROUP BY referer ORDER BY n DESC, referer LIMIT 10`,
+		qrcodeID, since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var r models.QRReferer
+		if err := rows.Scan(&r.Referer, &r.Count); err != nil {
+			return nil, err
+		}
+		stats.Referers = append(stats.Referers, r)
+	}
+	return stats, rows.Err()
+}
+
+func (db *DB) SaveQRLogo(ctx context.Context, logo *models.QRLogo) error {
+	return db.pool.QueryRow(ctx,
+		`INSERT INTO qr_logos (name, content_type, data, uploaded_by) VALUES ($1,$2,$3,$4) RETURNING id, created_at`,
+		logo.Name, logo.ContentType, logo.Data, logo.UploadedBy,
+	).Scan(&logo.ID, &logo.CreatedAt)
+}
+
+// ListQRLogos returns logos without their image data.
+func (db *DB) ListQRLogos(ctx context.Context) ([]models.QRLogo, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT id, name, content_type, COALESCE(uploaded_by, 0), created_at FROM qr_logos ORDER BY name, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	logos := []models.QRLogo{}
+	for rows.Next() {
+		var l models.QRLogo
+		if err := rows.Scan(&l.ID, &l.Name, &l.ContentType, &l.UploadedBy, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		logos = append(logos, l)
+	}
+	return logos, rows.Err()
+}
+
+func (db *DB) GetQRLogo(ctx context.Context, id int) (*models.QRLogo, error) {
+	var l models.QRLogo
+	err := db.pool.QueryRow(ctx,
+		`SELECT id, name, content_type, data, COALESCE(uploaded_by, 0), created_at FROM qr_logos WHERE id = $1`, id,
+	).Scan(&l.ID, &l.Name, &l.ContentType, &l.Data, &l.UploadedBy, &l.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
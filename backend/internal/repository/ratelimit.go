@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MigrateRateLimits creates the table behind the Postgres rate limit store.
+func (db *DB) MigrateRateLimits(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS rate_limits (
+			key VARCHAR(200) PRIMARY KEY,
+			tat TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_rate_limits_tat ON rate_limits(tat)`,
+	}
+	for _, q := range queries {
+		if _, err := db.pool.Exec(ctx, q); err != nil {
+			return fmt.Errorf("migration failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// TakeRateLimitToken advances the key's theoretical arrival time by interval
+// unless that would put it more than tolerance ahead of now. It returns the
+// stored time afterwards and whether the request was allowed; the check and
+// the update are one statement so concurrent servers cannot both take the
+// last token.
+func (db *DB) TakeRateLimitToken(ctx context.Context, key string, now time.Time, interval, tolerance time.Duration) (time.Time, bool, error) {
+	now = now.UTC()
+	// The sweeper may delete the row between the two statements; the upsert
+	// then inserts a fresh bucket, so try again rather than refusing.
+	for attempt := 0; attempt < 3; attempt++ {
+		var tat time.Time
+		err := db.pool.QueryRow(ctx,
+			`INSERT INTO rate_limits (key, tat) VALUES ($1, $2::timestamp + make_interval(secs => $3))
+			 ON CONFLICT (key) DO UPDATE SET tat = GREATEST(rate_limits.tat, $2::timestamp) + make_interval(secs => $3)
+			 WHERE GREATEST(rate_limits.tat, $2::timestamp) + make_interval(secs => $3) <= $2::timestamp + make_interval(secs => $4)
+			 RETURNING tat`,
+			key, now, interval.Seconds(), tolerance.Seconds()).Scan(&tat)
+		if err == nil {
+			return tat, true, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, false, err
+		}
+
+		// The WHERE clause rejected the update: the bucket is empty.
+		err = db.pool.QueryRow(ctx, `SELECT tat FROM rate_limits WHERE key = $1`, key).Scan(&tat)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		return tat, false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("rate limit %s: bucket kept disappearing", key)
+}
+
+// DeleteIdleRateLimits removes buckets that have refilled completely.
+func (db *DB) DeleteIdleRateLimits(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := db.pool.Exec(ctx, `DELETE FROM rate_limits WHERE tat < $1`, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
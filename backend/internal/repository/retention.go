@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AnonymizedContent replaces message bodies when retention runs in anonymize mode.
+const AnonymizedContent = "[noi dung da xoa theo chinh sach luu tru]"
+
+// retentionTables whitelists the tables the purge job may touch, so table names
+// can be safely formatted into SQL. The value is run when rows are anonymized,
+// as a data-modifying CTE over the anonymized ids in done, for copies kept
+// elsewhere: a counselor summary quotes the student and goes with its message.
+var retentionTables = map[string]string{
+	"chat_messages":   "",
+	"direct_messages": `, summaries AS (DELETE FROM chat_summaries WHERE message_id IN (SELECT id FROM done))`,
+}
+
+func checkRetentionTable(table string) error {
+	if _, ok := retentionTables[table]; !ok {
+		return fmt.Errorf("table %q is not subject to retention", table)
+	}
+	return nil
+}
+
+// CountExpired counts rows older than before that a purge would still touch.
+func (db *DB) CountExpired(ctx context.Context, table string, before time.Time, anonymize bool) (int64, error) {
+	if err := checkRetentionTable(table); err != nil {
+		return 0, err
+	}
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE created_at < $1`, table)
+	args := []interface{}{before}
+	if anonymize {
+		query += ` AND content <> $2`
+		args = append(args, AnonymizedContent)
+	}
+	var count int64
+	if err := db.pool.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count expired %s: %w", table, err)
+	}
+	return count, nil
+}
+
+// PurgeExpiredBatch deletes (or anonymizes) up to limit rows older than before and
+// returns how many rows were affected. Callers loop until it returns 0.
+func (db *DB) PurgeExpiredBatch(ctx context.Context, table string, before time.Time, limit int, anonymize bool) (int64, error) {
+	if err := checkRetentionTable(table); err != nil {
+		return 0, err
+	}
+	if anonymize {
+		var n int64
+		err := db.pool.QueryRow(ctx, fmt.Sprintf(
+			`WITH done AS (UPDATE %[1]s SET content = $3 WHERE id IN (SELECT id FROM %[1]s WHERE created_at < $1 AND content <> $3 ORDER BY id LIMIT $2) RETURNING id)%[2]s
+			 SELECT COUNT(*) FROM done`,
+			table, retentionTables[table]), before, limit, AnonymizedContent).Scan(&n)
+		if err != nil {
+			return 0, fmt.Errorf("purge %s: %w", table, err)
+		}
+		return n, nil
+	}
+	tag, err := db.pool.Exec(ctx, fmt.Sprintf(
+		`DELETE FROM %[1]s WHERE id IN (SELECT id FROM %[1]s WHERE created_at < $1 ORDER BY id LIMIT $2)`,
+		table), before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("purge %s: %w", table, err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteEmptyChatSessions removes sessions created before the cutoff that no
+// longer have any messages, typically after their messages were purged.
+func (db *DB) DeleteEmptyChatSessions(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := db.pool.Exec(ctx,
+		`DELETE FROM chat_sessions s WHERE s.created_at < $1
+		 AND NOT EXISTS (SELECT 1 FROM chat_messages m WHERE m.session_id = s.session_id)`,
+		before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delete empty chat sessions: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
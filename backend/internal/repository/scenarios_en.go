@@ -0,0 +1,27 @@
+package repository
+
+// englishScenarios are Buddy's replies for international students. Triggers
+// hold the English keywords buildAIResponse looks up.
+var englishScenarios = []seedScenario{
+	{"stress", "exam test pressure", "Exams can feel huge, and it is completely normal to feel pressure before them. Try breaking your revision into small blocks and take a few slow breaths before each one.", "Pomodoro: study for 25 minutes, then rest for 5"},
+	{"stress", "too much homework overwhelmed deadline", "When everything is due at once it is easy to feel overwhelmed. Write down every task, pick the one due first, and only think about that one for now.", "List tasks by deadline and do the smallest urgent one first"},
+	{"stress", "parents expectations pressure family", "Feeling that you have to meet your family's expectations can be heavy. Your worth is not only your grades. It may help to tell them honestly how you feel.", "Pick a calm moment to talk with your parents about how much pressure you feel"},
+	{"anxiety", "worried anxious future", "Worrying about the future is something many students go through. Try to focus on what you can control today, one small step at a time.", "Write your worries down, then circle the ones you can act on this week"},
+	{"anxiety", "panic attack heart racing can't breathe", "A panic attack feels frightening, but it passes. Breathe in for 4 seconds, hold for 4, breathe out for 4. Notice five things you can see around you.", "Box breathing: in 4, hold 4, out 4, hold 4"},
+	{"anxiety", "nervous presentation judged", "Feeling nervous about being judged is very common. Most people are focused on themselves far more than on you. Practising out loud a few times really helps.", "Rehearse your presentation out loud to a friend or a mirror"},
+	{"motivation", "unmotivated lazy procrastinate", "Losing motivation happens to everyone. Remind yourself why you started, and set one tiny goal you can finish in 10 minutes.", "Reward yourself after each small goal"},
+	{"motivation", "give up pointless failure", "When things feel pointless it is hard to keep going. A setback is information, not a verdict on you. What is one small thing that went okay this week?", "Keep a list of small wins and read it when you want to give up"},
+	{"focus", "can't focus concentrate distracted", "Trouble concentrating has many causes. Try putting your phone in another room and listening to Alpha brainwave audio while you study.", "Create a quiet study space without your phone"},
+	{"focus", "phone social media tiktok", "Social media is designed to grab attention, so it is not a personal failing. Try app timers, or keep your phone out of reach during study blocks.", "Turn on focus mode or app limits during study time"},
+	{"sleep", "can't sleep insomnia", "Sleep matters a lot for learning. Try listening to Theta brainwave audio before bed and keep the same bedtime every night.", "No phone for an hour before sleep"},
+	{"sleep", "nightmare stay up late tired", "Staying up late and waking up tired builds up quickly. A regular routine and a wind-down hour without screens can reset your sleep over a week or two.", "Go to bed and wake up at the same time, even at weekends"},
+	{"loneliness", "lonely alone no friends", "Feeling lonely is more common than it looks, especially in a new country or school. You are not alone in feeling this. Is there a club or class activity you could try?", "Say hello to one new person this week"},
+	{"loneliness", "homesick new country international", "Missing home is a normal part of studying abroad. Keep regular calls with family, and look for other international students who understand how it feels.", "Schedule a weekly call home and join an international student group"},
+	{"loneliness", "breakup broke up friends conflict", "Breakups and fallouts with friends hurt a lot. Give yourself time to feel it, and lean on people who care about you.", "Talk to someone you trust instead of keeping it inside"},
+	{"self-esteem", "worthless not good enough", "Feeling not good enough is painful, but feelings are not facts. Would you say the same thing to a friend in your situation?", "Write down three things you did well today"},
+	{"self-esteem", "ugly appearance body", "Worries about how you look can take up a lot of space. Your value is not your appearance. Try to notice what your body lets you do, not just how it looks.", "Take a break from comparing yourself on social media"},
+	{"self-esteem", "bad grades stupid", "One bad grade does not make you stupid. Grades measure one moment, not your ability to learn and grow.", "Ask your teacher which topic to review first"},
+	{"depression", "sad crying empty", "I am sorry you are feeling this way. Sadness that stays for a long time deserves care and support. Would you like to tell me more about what has been happening?", "Talk with a school counselor or a trusted adult this week"},
+	{"depression", "hopeless depressed numb", "Feeling hopeless or numb is a symptom of depression, not a reflection of reality. It can get better with support and treatment.", "Reach out to the school counselor today - you do not have to wait until it feels 'bad enough'"},
+	{"depression", "suicide kill myself want to die self harm", "If you are thinking about hurting yourself or ending your life, this is an emergency and you deserve help right now. You do not have to face this alone. Please call emergency services or a crisis line now, or tell a trusted adult immediately.", "Emergency: 115. Vietnam mental health hotline: 1800 599 920 (free, 24/7, Vietnamese). International: findahelpline.com"},
+}
@@ -0,0 +1,333 @@
+package repository
+
+import (
+	"context"
+	"edu-web-backend/internal/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MigrateWellbeing creates the student wellbeing tables.
+func (db *DB) MigrateWellbeing(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS mood_entries (
+			id SERIAL PRIMARY KEY,
+			user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			score SMALLINT NOT NULL CHECK (score BETWEEN 1 AND 5),
+			tags TEXT[] NOT NULL DEFAULT '{}',
+			note TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_mood_entries_user_time ON mood_entries(user_id, created_at)`,
+		`CREATE TABLE IF NOT EXISTS screening_results (
+			id SERIAL PRIMARY KEY,
+			user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			questionnaire VARCHAR(20) NOT NULL,
+			answers INT[] NOT NULL,
+			total INT NOT NULL,
+			severity VARCHAR(30) NOT NULL,
+			crisis BOOLEAN NOT NULL DEFAULT FALSE,
+			created_at TIMESTAMP DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_screening_results_user ON screening_results(user_id, created_at)`,
+		`CREATE TABLE IF NOT EXISTS safety_plans (
+			id SERIAL PRIMARY KEY,
+			user_id INT UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			counselor_id INT REFERENCES users(id) ON DELETE SET NULL,
+			current_version INT NOT NULL DEFAULT 1,
+			created_at TIMESTAMP DEFAULT NOW(),
+			updated_at TIMESTAMP DEFAULT NOW()
+		)`,
+		`CREATE TABLE IF NOT EXISTS safety_plan_versions (
+			plan_id INT NOT NULL REFERENCES safety_plans(id) ON DELETE CASCADE,
+			version INT NOT NULL,
+			content JSONB NOT NULL,
+			edited_by INT REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP DEFAULT NOW(),
+			PRIMARY KEY (plan_id, version)
+		)`,
+	}
+	for _, q := range queries {
+		if _, err := db.pool.Exec(ctx, q); err != nil {
+			return fmt.Errorf("wellbeing migration error: %w", err)
+		}
+	}
+	return nil
+}
+
+func (db *DB) CreateMoodEntry(ctx context.Context, userID, score int, tags []string, note string) (*models.MoodEntry, error) {
+	var m models.MoodEntry
+	err := db.pool.QueryRow(ctx,
+		`INSERT INTO mood_entries (user_id, score, tags, note) VALUES ($1, $2, $3, $4) RETURNING id, user_id, score, tags, note, created_at`,
+		userID, score, tags, note,
+	).Scan(&m.ID, &m.UserID, &m.Score, &m.Tags, &m.Note, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetMoodEntriesSince returns the user's entries created at or after since, oldest first.
+func (db *DB) GetMoodEntriesSince(ctx context.Context, userID int, since time.Time) ([]models.MoodEntry, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT id, user_id, score, tags, note, created_at FROM mood_entries WHERE user_id = $1 AND created_at >= $2 ORDER BY created_at ASC`,
+		userID, since.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []models.MoodEntry
+	for rows.Next() {
+		var m models.MoodEntry
+		if err := rows.Scan(&m.ID, &m.UserID, &m.Score, &m.Tags, &m.Note, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []models.MoodEntry{}
+	}
+	return entries, nil
+}
+
+// GetRecentDailyMoods returns the average score of the user's most recent
+// logged days in loc, newest first, at most limit days.
+func (db *DB) GetRecentDailyMoods(ctx context.Context, userID, limit int, loc *time.Location) ([]models.DailyMood, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT (created_at AT TIME ZONE 'UTC' AT TIME ZONE $3)::date AS day, AVG(score)::float8
+		 FROM mood_entries WHERE user_id = $1 GROUP BY day ORDER BY day DESC LIMIT $2`,
+		userID, limit, loc.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var days []models.DailyMood
+	for rows.Next() {
+		var d models.DailyMood
+		if err := rows.Scan(&d.Day, &d.Average); err != nil {
+			return nil, err
+		}
+		days = append(days, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return days, nil
+}
+
+func (db *DB) SaveScreeningResult(ctx context.Context, r *models.ScreeningResult) error {
+	return db.pool.QueryRow(ctx,
+		`INSERT INTO screening_results (user_id, questionnaire, answers, total, severity, crisis) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at`,
+		r.UserID, r.Questionnaire, r.Answers, r.Total, r.Severity, r.Crisis,
+	).Scan(&r.ID, &r.CreatedAt)
+}
+
+// GetScreeningResults returns the user's results, newest first. An empty
+// questionnaire returns results for all questionnaires.
+func (db *DB) GetScreeningResults(ctx context.Context, userID int, questionnaire string) ([]models.ScreeningResult, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT id, user_id, questionnaire, answers, total, severity, crisis, created_at FROM screening_results
+		 WHERE user_id = $1 AND ($2 = '' OR questionnaire = $2) ORDER BY created_at DESC LIMIT 100`,
+		userID, questionnaire,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var results []models.ScreeningResult
+	for rows.Next() {
+		var r models.ScreeningResult
+		if err := rows.Scan(&r.ID, &r.UserID, &r.Questionnaire, &r.Answers, &r.Total, &r.Severity, &r.Crisis, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if results == nil {
+		results = []models.ScreeningResult{}
+	}
+	return results, nil
+}
+
+// GetScreeningSummary aggregates results since the given time by questionnaire
+// and severity, using only each student's latest submission per questionnaire.
+func (db *DB) GetScreeningSummary(ctx context.Context, since time.Time) ([]models.ScreeningSummary, error) {
+	rows, err := db.pool.Query(ctx,
+		`WITH latest AS (
+			SELECT DISTINCT ON (user_id, questionnaire) user_id, questionnaire, severity, crisis
+			FROM screening_results WHERE created_at >= $1
+			ORDER BY user_id, questionnaire, created_at DESC
+		), totals AS (
+			SELECT questionnaire, severity, COUNT(*) AS submissions
+			FROM screening_results WHERE created_at >= $1
+			GROUP BY questionnaire, severity
+		)
+		SELECT t.questionnaire, t.severity, t.submissions,
+		       COUNT(l.user_id), COUNT(l.user_id) FILTER (WHERE l.crisis)
+		FROM totals t
+		LEFT JOIN latest l ON l.questionnaire = t.questionnaire AND l.severity = t.severity
+		GROUP BY t.questionnaire, t.severity, t.submissions
+		ORDER BY t.questionnaire, t.severity`,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var summary []models.ScreeningSummary
+	for rows.Next() {
+		var s models.ScreeningSummary
+		if err := rows.Scan(&s.Questionnaire, &s.Severity, &s.Submissions, &s.Students, &s.Crisis); err != nil {
+			return nil, err
+		}
+		summary = append(summary, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if summary == nil {
+		summary = []models.ScreeningSummary{}
+	}
+	return summary, nil
+}
+
+const safetyPlanColumns = `p.id, p.user_id, p.counselor_id, p.current_version, v.content, COALESCE(v.edited_by, 0), p.created_at, p.updated_at`
+
+func scanSafetyPlan(row pgx.Row) (*models.SafetyPlan, error) {
+	var p models.SafetyPlan
+	err := row.Scan(&p.ID, &p.UserID, &p.CounselorID, &p.Version, &p.Content, &p.EditedBy, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetSafetyPlan returns the current version of the student's plan, or nil.
+func (db *DB) GetSafetyPlan(ctx context.Context, userID int) (*models.SafetyPlan, error) {
+	return scanSafetyPlan(db.pool.QueryRow(ctx,
+		`SELECT `+safetyPlanColumns+` FROM safety_plans p
+		 JOIN safety_plan_versions v ON v.plan_id = p.id AND v.version = p.current_version
+		 WHERE p.user_id = $1`,
+		userID,
+	))
+}
+
+// SaveSafetyPlan stores content as a new version of the student's plan,
+// creating the plan on first save.
+func (db *DB) SaveSafetyPlan(ctx context.Context, userID, editorID int, content models.SafetyPlanContent) (*models.SafetyPlan, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("save safety plan begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var planID, version int
+	err = tx.QueryRow(ctx,
+		`INSERT INTO safety_plans (user_id) VALUES ($1)
+		 ON CONFLICT (user_id) DO UPDATE SET current_version = safety_plans.current_version + 1, updated_at = NOW()
+		 RETURNING id, current_version`,
+		userID,
+	).Scan(&planID, &version)
+	if err != nil {
+		return nil, fmt.Errorf("save safety plan: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO safety_plan_versions (plan_id, version, content, edited_by) VALUES ($1, $2, $3, $4)`,
+		planID, version, content, editorID,
+	); err != nil {
+		return nil, fmt.Errorf("save safety plan version: %w", err)
+	}
+
+	plan, err := scanSafetyPlan(tx.QueryRow(ctx,
+		`SELECT `+safetyPlanColumns+` FROM safety_plans p
+		 JOIN safety_plan_versions v ON v.plan_id = p.id AND v.version = p.current_version
+		 WHERE p.id = $1`,
+		planID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("save safety plan commit: %w", err)
+	}
+	return plan, nil
+}
+
+func (db *DB) GetSafetyPlanVersions(ctx context.Context, userID int) ([]models.SafetyPlanVersion, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT v.version, v.content, COALESCE(v.edited_by, 0), v.created_at FROM safety_plan_versions v
+		 JOIN safety_plans p ON p.id = v.plan_id WHERE p.user_id = $1 ORDER BY v.version DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var versions []models.SafetyPlanVersion
+	for rows.Next() {
+		var v models.SafetyPlanVersion
+		if err := rows.Scan(&v.Version, &v.Content, &v.EditedBy, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if versions == nil {
+		versions = []models.SafetyPlanVersion{}
+	}
+	return versions, nil
+}
+
+// SetSafetyPlanCounselor shares the plan with a counselor, or stops sharing when
+// counselorID is nil. It reports whether the student has a plan.
+func (db *DB) SetSafetyPlanCounselor(ctx context.Context, userID int, counselorID *int) (bool, error) {
+	tag, err := db.pool.Exec(ctx, `UPDATE safety_plans SET counselor_id = $1 WHERE user_id = $2`, counselorID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetSharedSafetyPlans returns the current plans shared with a counselor.
+func (db *DB) GetSharedSafetyPlans(ctx context.Context, counselorID int) ([]models.SafetyPlan, error) {
+	rows, err := db.pool.Query(ctx,
+		`SELECT `+safetyPlanColumns+` FROM safety_plans p
+		 JOIN safety_plan_versions v ON v.plan_id = p.id AND v.version = p.current_version
+		 WHERE p.counselor_id = $1 ORDER BY p.updated_at DESC`,
+		counselorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var plans []models.SafetyPlan
+	for rows.Next() {
+		plan, err := scanSafetyPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, *plan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if plans == nil {
+		plans = []models.SafetyPlan{}
+	}
+	return plans, nil
+}
@@ -0,0 +1,133 @@
+// Package retention enforces how long chat content is kept. Expired rows are
+// deleted or anonymized in batches, either on a schedule inside the server or
+// on demand through the "purge" subcommand.
+package retention
+
+import (
+	"context"
+	"edu-web-backend/internal/repository"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// Policy is the retention period for one table. A zero MaxAge keeps rows forever.
+type Policy struct {
+	Table  string
+	MaxAge time.Duration
+}
+
+// TableReport describes what a purge run did (or would do) to one table.
+type TableReport struct {
+	Table    string
+	Cutoff   time.Time
+	Expired  int64
+	Affected int64
+}
+
+// Report is the outcome of one purge run.
+type Report struct {
+	DryRun          bool
+	Anonymize       bool
+	Tables          []TableReport
+	SessionsDeleted int64
+}
+
+func (r Report) String() string {
+	var b strings.Builder
+	action := "deleted"
+	if r.Anonymize {
+		action = "anonymized"
+	}
+	if r.DryRun {
+		fmt.Fprintf(&b, "retention dry run (mode: %s)\n", action)
+	}
+	for _, t := range r.Tables {
+		if r.DryRun {
+			fmt.Fprintf(&b, "  %-16s older than %s: %d rows would be %s\n", t.Table, t.Cutoff.Format(time.RFC3339), t.Expired, action)
+		} else {
+			fmt.Fprintf(&b, "  %-16s older than %s: %d rows %s\n", t.Table, t.Cutoff.Format(time.RFC3339), t.Affected, action)
+		}
+	}
+	if !r.DryRun && r.SessionsDeleted > 0 {
+		fmt.Fprintf(&b, "  %d empty chat sessions deleted\n", r.SessionsDeleted)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+type Purger struct {
+	db        *repository.DB
+	policies  []Policy
+	batchSize int
+	anonymize bool
+}
+
+func NewPurger(db *repository.DB, policies []Policy, batchSize int, anonymize bool) *Purger {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &Purger{db: db, policies: policies, batchSize: batchSize, anonymize: anonymize}
+}
+
+// Run applies every policy once. With dryRun it only counts expired rows.
+func (p *Purger) Run(ctx context.Context, dryRun bool) (Report, error) {
+	report := Report{DryRun: dryRun, Anonymize: p.anonymize}
+	now := time.Now()
+	for _, pol := range p.policies {
+		if pol.MaxAge <= 0 {
+			continue
+		}
+		tr := TableReport{Table: pol.Table, Cutoff: now.Add(-pol.MaxAge)}
+
+		expired, err := p.db.CountExpired(ctx, pol.Table, tr.Cutoff, p.anonymize)
+		if err != nil {
+			return report, err
+		}
+		tr.Expired = expired
+
+		if !dryRun {
+			for {
+				n, err := p.db.PurgeExpiredBatch(ctx, pol.Table, tr.Cutoff, p.batchSize, p.anonymize)
+				if err != nil {
+					return report, err
+				}
+				tr.Affected += n
+				if n < int64(p.batchSize) {
+					break
+				}
+			}
+			if pol.Table == "chat_messages" && !p.anonymize {
+				n, err := p.db.DeleteEmptyChatSessions(ctx, tr.Cutoff)
+				if err != nil {
+					return report, err
+				}
+				report.SessionsDeleted = n
+			}
+		}
+		report.Tables = append(report.Tables, tr)
+	}
+	return report, nil
+}
+
+// Schedule runs the purge once at startup and then every interval until ctx is done.
+func (p *Purger) Schedule(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		report, err := p.Run(ctx, false)
+		if err != nil {
+			log.Printf("Retention purge error: %v", err)
+		} else {
+			log.Printf("Retention purge finished:\n%s", report)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Package screening defines validated self-report questionnaires (PHQ-9,
+// GAD-7) and scores submitted answers into severity bands.
+package screening
+
+import "fmt"
+
+type Option struct {
+	Value int    `json:"value"`
+	Label string `json:"label"`
+}
+
+// Band maps a total score range (inclusive) to a severity level.
+type Band struct {
+	Min      int    `json:"min"`
+	Max      int    `json:"max"`
+	Severity string `json:"severity"`
+	Label    string `json:"label"`
+}
+
+type Questionnaire struct {
+	Code         string   `json:"code"`
+	Title        string   `json:"title"`
+	Instructions string   `json:"instructions"`
+	Questions    []string `json:"questions"`
+	Options      []Option `json:"options"`
+	Bands        []Band   `json:"bands"`
+
+	// CrisisThreshold is the total score at or above which the crisis path runs.
+	CrisisThreshold int `json:"-"`
+	// CrisisItems are 0-based question indexes where any nonzero answer is a crisis.
+	CrisisItems []int `json:"-"`
+}
+
+// Result is the scored outcome of one submission.
+type Result struct {
+	Total    int    `json:"total"`
+	Severity string `json:"severity"`
+	Label    string `json:"label"`
+	Crisis   bool   `json:"crisis"`
+}
+
+// Frequency options shared by PHQ-9 and GAD-7 ("over the last 2 weeks").
+var frequencyOptions = []Option{
+	{0, "Không ngày nào"},
+	{1, "Vài ngày"},
+	{2, "Hơn một nửa số ngày"},
+	{3, "Gần như mỗi ngày"},
+}
+
+var questionnaires = map[string]Questionnaire{
+	"phq9": {
+		Code:         "phq9",
+		Title:        "PHQ-9 - Bảng câu hỏi sức khỏe bệnh nhân (trầm cảm)",
+		Instructions: "Trong 2 tuần qua, bạn có thường xuyên bị làm phiền bởi những vấn đề sau không?",
+		Questions: []string{
+			"Ít hứng thú hoặc không thấy vui khi làm bất cứ việc gì",
+			"Cảm thấy buồn, chán nản hoặc tuyệt vọng",
+			"Khó ngủ, ngủ không yên giấc hoặc ngủ quá nhiều",
+			"Cảm thấy mệt mỏi hoặc thiếu năng lượng",
+			"Ăn không ngon miệng hoặc ăn quá nhiều",
+			"Cảm thấy tệ về bản thân, cho rằng mình là người thất bại hoặc đã làm bản thân hay gia đình thất vọng",
+			"Khó tập trung vào việc gì đó, chẳng hạn như đọc sách hoặc xem tivi",
+			"Đi lại hoặc nói chậm đến mức người khác có thể nhận thấy, hoặc ngược lại, bồn chồn đứng ngồi không yên hơn bình thường",
+			"Có ý nghĩ rằng mình chết đi thì tốt hơn, hoặc muốn làm hại bản thân theo cách nào đó",
+		},
+		Options: frequencyOptions,
+		Bands: []Band{
+			{0, 4, "minimal", "Không có hoặc rất nhẹ"},
+			{5, 9, "mild", "Nhẹ"},
+			{10, 14, "moderate", "Trung bình"},
+			{15, 19, "moderately_severe", "Khá nặng"},
+			{20, 27, "severe", "Nặng"},
+		},
+		CrisisThreshold: 20,
+		CrisisItems:     []int{8},
+	},
+	"gad7": {
+		Code:         "gad7",
+		Title:        "GAD-7 - Thang đo rối loạn lo âu lan tỏa",
+		Instructions: "Trong 2 tuần qua, bạn có thường xuyên bị làm phiền bởi những vấn đề sau không?",
+		Questions: []string{
+			"Cảm thấy bồn chồn, lo âu hoặc căng thẳng",
+			"Không thể ngừng hoặc kiểm soát được sự lo lắng",
+			"Lo lắng quá nhiều về nhiều việc khác nhau",
+			"Khó thư giãn",
+			"Bồn chồn đến mức khó ngồi yên",
+			"Dễ bực bội hoặc cáu kỉnh",
+			"Cảm thấy sợ hãi như thể điều gì đó tồi tệ sắp xảy ra",
+		},
+		Options: frequencyOptions,
+		Bands: []Band{
+			{0, 4, "minimal", "Không có hoặc rất nhẹ"},
+			{5, 9, "mild", "Nhẹ"},
+			{10, 14, "moderate", "Trung bình"},
+			{15, 21, "severe", "Nặng"},
+		},
+		CrisisThreshold: 15,
+	},
+}
+
+// Codes lists the available questionnaires in display order.
+var Codes = []string{"phq9", "gad7"}
+
+// Get returns the questionnaire with the given code.
+func Get(code string) (Questionnaire, bool) {
+	q, ok := questionnaires[code]
+	return q, ok
+}
+
+// Score validates answers and computes the total, severity band and crisis flag.
+func (q Questionnaire) Score(answers []int) (Result, error) {
+	if len(answers) != len(q.Questions) {
+		return Result{}, fmt.Errorf("expected %d answers, got %d", len(q.Questions), len(answers))
+	}
+	maxValue := q.Options[len(q.Options)-1].Value
+	var r Result
+	for i, a := range answers {
+		if a < 0 || a > maxValue {
+			return Result{}, fmt.Errorf("answer %d must be between 0 and %d", i+1, maxValue)
+		}
+		r.Total += a
+	}
+	for _, b := range q.Bands {
+		if r.Total >= b.Min && r.Total <= b.Max {
+			r.Severity = b.Severity
+			r.Label = b.Label
+			break
+		}
+	}
+	r.Crisis = q.CrisisThreshold > 0 && r.Total >= q.CrisisThreshold
+	for _, i := range q.CrisisItems {
+		if answers[i] > 0 {
+			r.Crisis = true
+		}
+	}
+	return r, nil
+}
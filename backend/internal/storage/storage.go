@@ -0,0 +1,27 @@
+// Package storage keeps uploaded media files. Handlers only see the Store
+// interface, so the local disk can later be swapped for object storage.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned by Open and Delete for a missing key.
+var ErrNotFound = errors.New("stored file not found")
+
+// Object is an open stored file. Seeking lets it be served with Range
+// requests.
+type Object interface {
+	io.ReadSeekCloser
+}
+
+// Store saves and serves files under opaque keys chosen by the caller.
+type Store interface {
+	// Put writes r under key and returns the number of bytes stored. A
+	// failed Put leaves nothing behind.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (Object, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,146 @@
+// Package summary condenses a Buddy chat session into a handoff summary for a
+// counselor. The Summarizer interface leaves room for a model-based
+// implementation; RuleBased is the default and uses only the chatbot classifier.
+package summary
+
+import (
+	"edu-web-backend/internal/chatbot"
+	"edu-web-backend/internal/exercise"
+	"edu-web-backend/internal/models"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Conversation is everything a summarizer may use.
+type Conversation struct {
+	SessionID string
+	Messages  []models.ChatMessage
+	Exercises []models.ExerciseLogEntry
+}
+
+type Summarizer interface {
+	Summarize(conv Conversation) (models.SessionSummary, error)
+}
+
+// RuleBased summarizes with the chatbot classifier: categories of the
+// student's messages over time, the longest categorized messages as key
+// statements, and every message that hit the crisis keywords.
+type RuleBased struct {
+	MaxStatements int
+	MaxLength     int
+}
+
+func NewRuleBased() RuleBased {
+	return RuleBased{MaxStatements: 5, MaxLength: 200}
+}
+
+func (r RuleBased) Summarize(conv Conversation) (models.SessionSummary, error) {
+	s := models.SessionSummary{
+		SessionID:     conv.SessionID,
+		Categories:    []models.CategorySpan{},
+		KeyStatements: []models.SummaryStatement{},
+		Exercises:     conv.Exercises,
+		CrisisFlags:   []models.SummaryStatement{},
+	}
+	if s.Exercises == nil {
+		s.Exercises = []models.ExerciseLogEntry{}
+	}
+	if len(conv.Messages) > 0 {
+		s.From = conv.Messages[0].CreatedAt
+		s.To = conv.Messages[len(conv.Messages)-1].CreatedAt
+	}
+
+	var candidates []models.SummaryStatement
+	for _, m := range conv.Messages {
+		if m.Role != "user" {
+			continue
+		}
+		s.Messages++
+		cls := chatbot.Classify(m.Content)
+		// The stored text is redacted; the flag saved with it was set from
+		// what the student actually wrote.
+		cls.Crisis = cls.Crisis || m.Crisis
+		st := models.SummaryStatement{At: m.CreatedAt, Text: r.truncate(m.Content), Category: cls.Category, Crisis: cls.Crisis}
+
+		if cls.Crisis {
+			s.CrisisFlags = append(s.CrisisFlags, st)
+		}
+		if cls.Category != "" {
+			if n := len(s.Categories); n > 0 && s.Categories[n-1].Category == cls.Category {
+				s.Categories[n-1].To = m.CreatedAt
+				s.Categories[n-1].Messages++
+			} else {
+				s.Categories = append(s.Categories, models.CategorySpan{Category: cls.Category, From: m.CreatedAt, To: m.CreatedAt, Messages: 1})
+			}
+			candidates = append(candidates, st)
+		}
+	}
+
+	// Crisis statements first, then the longest ones, shown in chronological order.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Crisis != candidates[j].Crisis {
+			return candidates[i].Crisis
+		}
+		return len(candidates[i].Text) > len(candidates[j].Text)
+	})
+	if len(candidates) > r.MaxStatements {
+		candidates = candidates[:r.MaxStatements]
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].At.Before(candidates[j].At) })
+	s.KeyStatements = append(s.KeyStatements, candidates...)
+	return s, nil
+}
+
+func (r RuleBased) truncate(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if r.MaxLength > 0 && len(runes) > r.MaxLength {
+		return string(runes[:r.MaxLength]) + "..."
+	}
+	return text
+}
+
+// Text renders the summary as the direct message a counselor receives.
+// Times are shown in loc.
+func Text(s models.SessionSummary, loc *time.Location) string {
+	var b strings.Builder
+	b.WriteString("[Tom tat Buddy] Hoc sinh da dong y chia se tom tat cuoc tro chuyen voi Buddy.")
+	if s.Messages > 0 {
+		fmt.Fprintf(&b, "\n%d tin nhan, tu %s den %s.", s.Messages, s.From.In(loc).Format("15:04 02/01/2006"), s.To.In(loc).Format("15:04 02/01/2006"))
+	}
+
+	if len(s.CrisisFlags) > 0 {
+		fmt.Fprintf(&b, "\n\nCANH BAO: %d tin nhan co dau hieu khung hoang:", len(s.CrisisFlags))
+		for _, st := range s.CrisisFlags {
+			fmt.Fprintf(&b, "\n- %s: \"%s\"", st.At.In(loc).Format("15:04 02/01"), st.Text)
+		}
+	}
+
+	if len(s.Categories) > 0 {
+		b.WriteString("\n\nChu de theo thoi gian:")
+		for _, c := range s.Categories {
+			fmt.Fprintf(&b, "\n- %s %s (%d tin nhan)", c.From.In(loc).Format("15:04 02/01"), c.Category, c.Messages)
+		}
+	}
+
+	if len(s.KeyStatements) > 0 {
+		b.WriteString("\n\nCau noi dang chu y:")
+		for _, st := range s.KeyStatements {
+			fmt.Fprintf(&b, "\n- \"%s\"", st.Text)
+		}
+	}
+
+	if len(s.Exercises) > 0 {
+		b.WriteString("\n\nBai tap da thu:")
+		for _, e := range s.Exercises {
+			name := e.Exercise
+			if ex, ok := exercise.Get(e.Exercise, chatbot.LangVietnamese); ok {
+				name = ex.Title
+			}
+			fmt.Fprintf(&b, "\n- %s (%s)", name, e.Status)
+		}
+	}
+	return b.String()
+}
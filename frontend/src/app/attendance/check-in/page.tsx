@@ -0,0 +1,60 @@
+'use client'
+import { Suspense, useEffect, useRef, useState } from 'react'
+import { useSearchParams } from 'next/navigation'
+import api, { ApiError } from '@/lib/api'
+
+interface CheckInResult {
+  session_id: number; title: string; already_checked: boolean
+}
+
+function CheckIn() {
+  const token = useSearchParams().get('t') ?? ''
+  const [result, setResult] = useState<CheckInResult | null>(null)
+  const [error, setError] = useState('')
+  const sent = useRef(false)
+
+  useEffect(() => {
+    // The code is only valid for a few seconds, so send it once, right away.
+    if (sent.current) return
+    sent.current = true
+    if (!token) {
+      setError('Lien ket diem danh khong hop le.')
+      return
+    }
+    api.post<{ data: CheckInResult }>('/attendance/check-in', { token })
+      .then(d => setResult(d.data))
+      .catch(err => {
+        if (err instanceof ApiError && err.status === 409) setError('Ma QR da doi. Hay quet lai ma dang hien tren man hinh.')
+        else if (err instanceof ApiError && err.status === 410) setError('Buoi diem danh da ket thuc.')
+        else setError(err instanceof ApiError ? err.message : 'Loi ket noi. Vui long thu lai.')
+      })
+  }, [token])
+
+  return (
+    <div className="bg-white rounded-2xl shadow-md p-8 text-center">
+      {result ? (
+        <>
+          <h1 className="text-2xl font-bold text-green-700 mb-2">{result.already_checked ? 'Ban da diem danh roi' : 'Diem danh thanh cong'}</h1>
+          <p className="text-gray-700">{result.title}</p>
+        </>
+      ) : error ? (
+        <>
+          <h1 className="text-2xl font-bold text-red-700 mb-2">Chua diem danh duoc</h1>
+          <p className="text-gray-700">{error}</p>
+        </>
+      ) : (
+        <p className="text-gray-500 animate-pulse">Dang diem danh...</p>
+      )}
+    </div>
+  )
+}
+
+export default function CheckInPage() {
+  return (
+    <div className="max-w-md mx-auto px-4 py-16">
+      <Suspense fallback={<p className="text-center text-gray-500">Dang tai...</p>}>
+        <CheckIn />
+      </Suspense>
+    </div>
+  )
+}
@@ -0,0 +1,120 @@
+'use client'
+import { useEffect, useState } from 'react'
+import api, { ApiError, apiUrl } from '@/lib/api'
+
+interface Session {
+  id: number; title: string; rotate_seconds: number
+  started_at: string; ends_at: string; check_ins: number
+}
+
+interface Code {
+  url: string; window: number; refresh_in: number; check_ins: number; ends_at: string
+}
+
+export default function AttendancePage() {
+  const [sessions, setSessions] = useState<Session[]>([])
+  const [active, setActive] = useState<Session | null>(null)
+  const [code, setCode] = useState<Code | null>(null)
+  const [title, setTitle] = useState('')
+  const [minutes, setMinutes] = useState(10)
+  const [error, setError] = useState('')
+
+  const loadSessions = () =>
+    api.get<{ data: Session[] }>('/attendance/sessions')
+      .then(d => setSessions(d.data))
+      .catch(err => setError(err instanceof ApiError ? err.message : 'Loi ket noi'))
+
+  useEffect(() => { loadSessions() }, [])
+
+  // Reload the code when it rotates; the image URL carries the window so the
+  // browser fetches the new one.
+  useEffect(() => {
+    if (!active) return
+    let timer: ReturnType<typeof setTimeout>
+    const tick = () => {
+      api.get<{ data: Code }>(`/attendance/sessions/${active.id}/code`)
+        .then(d => {
+          setCode(d.data)
+          timer = setTimeout(tick, Math.max(d.data.refresh_in, 1) * 1000)
+        })
+        .catch(() => { setActive(null); setCode(null); loadSessions() })
+    }
+    tick()
+    return () => clearTimeout(timer)
+  }, [active])
+
+  const start = async (e: React.FormEvent) => {
+    e.preventDefault()
+    setError('')
+    try {
+      const d = await api.post<{ data: Session }>('/attendance/sessions', { title, duration_minutes: minutes })
+      setTitle('')
+      setActive(d.data)
+    } catch (err) {
+      setError(err instanceof ApiError ? err.message : 'Loi ket noi')
+    }
+  }
+
+  const end = async () => {
+    if (!active) return
+    await api.post(`/attendance/sessions/${active.id}/end`).catch(console.error)
+    setActive(null)
+    setCode(null)
+    loadSessions()
+  }
+
+  const isOpen = (s: Session) => new Date(s.ends_at) > new Date()
+
+  return (
+    <div className="max-w-5xl mx-auto px-4 py-12">
+      <h1 className="text-4xl font-bold text-gray-900 mb-8 text-center">Diem danh bang Ma QR</h1>
+      {error && <div className="mb-6 p-3 bg-red-50 border border-red-200 text-red-700 rounded-lg text-sm">{error}</div>}
+
+      {active ? (
+        <div className="bg-white rounded-2xl shadow-md p-8 text-center mb-10">
+          <h2 className="text-2xl font-bold text-gray-900 mb-4">{active.title}</h2>
+          {code && (
+            <img src={apiUrl(`/attendance/sessions/${active.id}/code/image?size=640&w=${code.window}`)} alt="Ma diem danh"
+              className="mx-auto w-80 h-80 md:w-[28rem] md:h-[28rem]" />
+          )}
+          <p className="text-gray-600 mt-4">Da diem danh: <span className="font-bold text-gray-900">{code?.check_ins ?? 0}</span></p>
+          <p className="text-gray-400 text-sm">Ma doi moi {active.rotate_seconds} giay</p>
+          <button onClick={end} className="mt-6 bg-red-500 text-white px-6 py-2 rounded-xl font-semibold hover:bg-red-600 transition">Ket thuc</button>
+        </div>
+      ) : (
+        <form onSubmit={start} className="bg-white rounded-2xl shadow-md p-6 mb-10 flex flex-col md:flex-row gap-4 items-end">
+          <div className="flex-1 w-full">
+            <label className="block text-sm font-medium text-gray-700 mb-1">Lop / buoi hoc</label>
+            <input value={title} onChange={e => setTitle(e.target.value)} required placeholder="VD: 10A1 - Tiet 2"
+              className="w-full border border-gray-200 rounded-xl px-4 py-3 text-sm focus:outline-none focus:ring-2 focus:ring-orange-300" />
+          </div>
+          <div>
+            <label className="block text-sm font-medium text-gray-700 mb-1">So phut</label>
+            <input type="number" min={1} max={240} value={minutes} onChange={e => setMinutes(Number(e.target.value))}
+              className="w-28 border border-gray-200 rounded-xl px-4 py-3 text-sm focus:outline-none focus:ring-2 focus:ring-orange-300" />
+          </div>
+          <button type="submit" className="bg-orange-500 text-white px-6 py-3 rounded-xl font-semibold hover:bg-orange-600 transition">Bat dau</button>
+        </form>
+      )}
+
+      <h2 className="text-2xl font-bold text-gray-900 mb-4">Cac buoi diem danh</h2>
+      <div className="space-y-3">
+        {sessions.map(s => (
+          <div key={s.id} className="bg-white rounded-xl shadow-sm border border-gray-100 p-4 flex items-center justify-between">
+            <div>
+              <p className="font-semibold text-gray-900">{s.title}</p>
+              <p className="text-gray-400 text-xs">{new Date(s.started_at).toLocaleString('vi-VN')} - {s.check_ins} hoc sinh</p>
+            </div>
+            <div className="flex gap-2">
+              {isOpen(s) && !active && (
+                <button onClick={() => setActive(s)} className="bg-orange-100 text-orange-700 px-3 py-2 rounded-lg text-xs hover:bg-orange-200 transition">Hien ma</button>
+              )}
+              <a href={apiUrl(`/attendance/sessions/${s.id}/export`)}
+                className="bg-gray-100 text-gray-700 px-3 py-2 rounded-lg text-xs hover:bg-gray-200 transition">Tai CSV</a>
+            </div>
+          </div>
+        ))}
+      </div>
+    </div>
+  )
+}
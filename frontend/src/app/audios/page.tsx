@@ -5,7 +5,7 @@ import api from '@/lib/api'
 interface Audio {
   id: number; title: string; description: string
   drive_url: string; embed_url: string; category: string
-  duration: string; order: number; created_at: string
+  duration: string; order: number; media_id?: number; created_at: string
 }
 
 export default function AudiosPage() {
@@ -78,6 +78,10 @@ export default function AudiosPage() {
             <div key={a.id} className="bg-white rounded-xl shadow-md border border-gray-100 overflow-hidden">
               <div className="bg-gradient-to-br from-green-100 to-teal-100 p-6 flex flex-col items-center">
                 <div className={"text-5xl mb-3 " + (playing === a.id ? 'animate-bounce' : '')}></div>
+                {a.media_id && (
+                  <audio src={a.embed_url} className="w-full" controls preload="none"
+                    onPlay={() => setPlaying(a.id)} onPause={() => setPlaying(p => p === a.id ? null : p)} />
+                )}
               </div>
               <div className="p-5">
                 <div className="flex items-center justify-between mb-2">
@@ -7,33 +7,35 @@ interface ChatMessage {
   content: string; created_at: string
 }
 
-function genId() { return 'sess_' + Math.random().toString(36).substr(2, 9) + '_' + Date.now() }
-
 export default function ChatbotPage() {
   const [messages, setMessages] = useState<ChatMessage[]>([])
   const [input, setInput] = useState('')
   const [loading, setLoading] = useState(false)
-  const [sessionId] = useState(() => {
-    if (typeof window !== 'undefined') {
-      const s = sessionStorage.getItem('buddy_sid'); if (s) return s
-      const n = genId(); sessionStorage.setItem('buddy_sid', n); return n
-    }
-    return genId()
-  })
+  // Session IDs are issued by the backend on the first message and bound to this user/browser.
+  const [sessionId, setSessionId] = useState(() =>
+    typeof window !== 'undefined' ? sessionStorage.getItem('buddy_sid') ?? '' : '')
+  const [historyLoaded, setHistoryLoaded] = useState(false)
   const bottomRef = useRef<HTMLDivElement>(null)
 
   useEffect(() => {
+    if (historyLoaded) return
+    setHistoryLoaded(true)
+    const greeting: ChatMessage = { id: 0, session_id: sessionId, role: 'assistant',
+      content: 'Xin chao! Minh la Buddy AI - nguoi ban dong hanh tam ly 24/7 cua ban. Hay chia se bat cu dieu gi ban muon nhe!',
+      created_at: new Date().toISOString() }
+    if (!sessionId) { setMessages([greeting]); return }
     api.get<{ data?: ChatMessage[] }>(`/chat/${sessionId}`)
       .then(d => {
         if (d.data && d.data.length > 0) setMessages(d.data)
-        else setMessages([{ id: 0, session_id: sessionId, role: 'assistant',
-          content: 'Xin chao! Minh la Buddy AI - nguoi ban dong hanh tam ly 24/7 cua ban. Hay chia se bat cu dieu gi ban muon nhe!',
-          created_at: new Date().toISOString() }])
+        else setMessages([greeting])
+      })
+      .catch(() => {
+        // Session expired or belongs to someone else - start fresh on the next message.
+        sessionStorage.removeItem('buddy_sid')
+        setSessionId('')
+        setMessages([greeting])
       })
-      .catch(() => setMessages([{ id: 0, session_id: sessionId, role: 'assistant',
-        content: 'Xin chao! Minh la Buddy AI. Ban dang cam thay the nao hom nay?',
-        created_at: new Date().toISOString() }]))
-  }, [sessionId])
+  }, [sessionId, historyLoaded])
 
   useEffect(() => { bottomRef.current?.scrollIntoView({ behavior: 'smooth' }) }, [messages])
 
@@ -45,7 +47,11 @@ export default function ChatbotPage() {
     setInput('')
     setLoading(true)
     try {
-      const data = await api.post<{ response?: string }>('/chat', { session_id: sessionId, message: sentInput })
+      const data = await api.post<{ response?: string; session_id?: string }>('/chat', { session_id: sessionId, message: sentInput })
+      if (data.session_id && data.session_id !== sessionId) {
+        sessionStorage.setItem('buddy_sid', data.session_id)
+        setSessionId(data.session_id)
+      }
       const reply = data.response || 'Co loi xay ra. Vui long thu lai.'
       setMessages(p => [...p, { id: Date.now() + 1, session_id: sessionId, role: 'assistant', content: reply, created_at: new Date().toISOString() }])
     } catch (err) {
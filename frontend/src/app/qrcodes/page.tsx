@@ -1,10 +1,11 @@
 'use client'
 import { useEffect, useState } from 'react'
-import api, { ApiError } from '@/lib/api'
+import api, { ApiError, apiUrl } from '@/lib/api'
 
 interface QRCode {
   id: number; label: string; target_url: string
-  type: string; qr_data: string; created_at: string
+  type: string; short_url: string; created_at: string
+  visibility: 'public' | 'private'; expires_at: string | null; expired: boolean
 }
 
 export default function QRCodesPage() {
@@ -130,26 +131,27 @@ export default function QRCodesPage() {
           {qrcodes.map(qr => (
             <div key={qr.id} className="bg-white rounded-2xl shadow-md border border-gray-100 overflow-hidden hover:shadow-lg transition">
               <div className="bg-white p-6 flex items-center justify-center border-b">
-                {qr.qr_data ? (
-                  <img src={qr.qr_data} alt={qr.label} className="w-40 h-40 object-contain" />
-                ) : (
-                  <div className="w-40 h-40 bg-gray-100 rounded-xl flex items-center justify-center text-4xl"></div>
-                )}
+                <img src={apiUrl(`/qrcodes/${qr.id}/image?format=svg&size=320`)} alt={qr.label} className="w-40 h-40 object-contain" />
               </div>
               <div className="p-5">
                 <div className="flex items-center justify-between mb-2">
-                  <span className={"text-xs font-semibold px-2 py-1 rounded-full " + (typeColors[qr.type] || typeColors.general)}>{qr.type}</span>
+                  <div className="flex gap-1">
+                    <span className={"text-xs font-semibold px-2 py-1 rounded-full " + (typeColors[qr.type] || typeColors.general)}>{qr.type}</span>
+                    {qr.visibility === 'private' && <span className="text-xs font-semibold px-2 py-1 rounded-full bg-yellow-100 text-yellow-700">Rieng tu</span>}
+                    {qr.expired && <span className="text-xs font-semibold px-2 py-1 rounded-full bg-red-100 text-red-700">Het han</span>}
+                  </div>
                   <span className="text-gray-400 text-xs">{new Date(qr.created_at).toLocaleDateString('vi-VN')}</span>
                 </div>
                 <h3 className="font-bold text-gray-900 mb-2">{qr.label}</h3>
-                <p className="text-gray-400 text-xs mb-4 truncate">{qr.target_url}</p>
+                <p className="text-gray-400 text-xs truncate">{qr.target_url}</p>
+                <p className="text-gray-400 text-xs mb-4 truncate">{qr.short_url}</p>
                 <div className="flex gap-2">
                   <a href={qr.target_url} target="_blank" rel="noopener noreferrer"
                      className="flex-1 text-center bg-gray-100 text-gray-700 py-2 rounded-lg text-xs font-medium hover:bg-gray-200 transition">Mo link</a>
-                  {qr.qr_data && (
-                    <a href={qr.qr_data} download={"qr-" + qr.label + ".png"}
-                       className="bg-orange-100 text-orange-700 px-3 py-2 rounded-lg text-xs hover:bg-orange-200 transition">Tai</a>
-                  )}
+                  <a href={apiUrl(`/qrcodes/${qr.id}/image?format=png&size=1024`)} download={"qr-" + qr.label + ".png"}
+                     className="bg-orange-100 text-orange-700 px-3 py-2 rounded-lg text-xs hover:bg-orange-200 transition">Tai</a>
+                  <a href={apiUrl(`/qrcodes/${qr.id}/image?format=pdf`)} target="_blank" rel="noopener noreferrer"
+                     className="bg-orange-100 text-orange-700 px-3 py-2 rounded-lg text-xs hover:bg-orange-200 transition">PDF</a>
                 </div>
               </div>
             </div>
@@ -5,7 +5,7 @@ import api from '@/lib/api'
 interface Video {
   id: number; title: string; description: string
   drive_url: string; embed_url: string; thumbnail: string
-  category: string; order: number; created_at: string
+  category: string; duration: string; order: number; media_id?: number; created_at: string
 }
 
 export default function VideosPage() {
@@ -56,9 +56,23 @@ export default function VideosPage() {
         <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-6">
           {videos.map((v) => (
             <div key={v.id} className="bg-white rounded-xl shadow-md hover:shadow-lg transition border border-gray-100 overflow-hidden group">
-              <div className="bg-gradient-to-br from-blue-100 to-indigo-100 h-40 flex items-center justify-center text-6xl"></div>
+              {v.media_id ? (
+                <video src={v.embed_url} title={v.title} className="w-full bg-black aspect-video" controls preload="metadata" />
+              ) : v.embed_url ? (
+                <div className="relative" style={{ paddingBottom: '56.25%' }}>
+                  <iframe src={v.embed_url} title={v.title} className="absolute inset-0 w-full h-full" frameBorder="0"
+                    allow="autoplay; encrypted-media; picture-in-picture" allowFullScreen loading="lazy" />
+                </div>
+              ) : v.thumbnail ? (
+                <img src={v.thumbnail} alt={v.title} className="w-full h-40 object-cover" />
+              ) : (
+                <div className="bg-gradient-to-br from-blue-100 to-indigo-100 h-40 flex items-center justify-center text-6xl"></div>
+              )}
               <div className="p-5">
-                <span className="bg-blue-100 text-blue-700 text-xs font-semibold px-2 py-1 rounded-full mb-3 inline-block">{v.category}</span>
+                <div className="flex items-center justify-between mb-3">
+                  <span className="bg-blue-100 text-blue-700 text-xs font-semibold px-2 py-1 rounded-full">{v.category}</span>
+                  {v.duration && <span className="text-gray-400 text-xs">{v.duration}</span>}
+                </div>
                 <h3 className="font-bold text-gray-900 mb-2 group-hover:text-blue-700">{v.title}</h3>
                 <p className="text-gray-500 text-sm mb-4">{v.description}</p>
                 <a href={v.drive_url} target="_blank" rel="noopener noreferrer"
@@ -2,6 +2,9 @@ import { logout } from '@/lib/auth'
 
 const BASE_URL = process.env.NEXT_PUBLIC_API_URL ?? 'http://localhost:8080/api/v1'
 
+// apiUrl is for links the browser loads directly, such as rendered QR images.
+export const apiUrl = (path: string) => `${BASE_URL}${path}`
+
 export class ApiError extends Error {
   constructor(
     public status: number,